@@ -15,9 +15,11 @@
 package v1alpha1
 
 import (
+	"github.com/operator-framework/operator-sdk/pkg/status"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // NexusSpec defines the desired state of Nexus
@@ -28,6 +30,7 @@ type NexusSpec struct {
 	// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
 
 	// Number of pod replicas desired. Defaults to 0.
+	// Nexus OSS can't run several nodes against one data directory, so more than one replica requires `spec.highAvailability.mode`.
 	// +kubebuilder:validation:Maximum=100
 	// +kubebuilder:validation:Minimum=0
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
@@ -105,10 +108,287 @@ type NexusSpec struct {
 	// +optional
 	ReadinessProbe *NexusProbe `json:"readinessProbe,omitempty"`
 
+	// StartupProbe describes how the Nexus container startup probe should work.
+	// Liveness and readiness probes are only performed after it succeeds, so it should cover the time the server takes to start.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	StartupProbe *NexusProbe `json:"startupProbe,omitempty"`
+
 	// ServerOperations describes the options for the operations performed on the deployed server instance
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
 	// +optional
 	ServerOperations ServerOperationsOpts `json:"serverOperations,omitempty"`
+
+	// JVM describes how the Nexus server JVM should be tuned
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	JVM NexusJVM `json:"jvm,omitempty"`
+
+	// Env is a list of additional environment variables to set in the Nexus container
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// EnvFrom is a list of sources to populate environment variables in the Nexus container
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
+	// Volumes is a list of additional volumes that can be mounted by the Nexus container
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// VolumeMounts is a list of additional volumes to mount into the Nexus container's filesystem
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// Sidecars is a list of additional containers to run alongside the Nexus container in the same pod, such as a log shipper
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+
+	// InitContainers is a list of containers to run before the Nexus container is started, such as one fixing volume permissions
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+
+	// NodeSelector is a selector which must match a node's labels for the Nexus pod to be scheduled on that node
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations allow the Nexus pod to be scheduled on nodes with matching taints
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity describes the scheduling constraints of the Nexus pod
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Affinity"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:nodeAffinity"
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints describes how the Nexus pods ought to spread across topology domains
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName is the name of the PriorityClass of the Nexus pod
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Priority Class Name"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Labels added to every resource created by the Operator for this instance.
+	// The labels used by the Operator to select the Nexus pods can't be overridden.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations added to every resource created by the Operator for this instance
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// PodLabels added to the Nexus pods.
+	// The labels used by the Operator to select the Nexus pods can't be overridden.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	PodLabels map[string]string `json:"podLabels,omitempty"`
+
+	// PodAnnotations added to the Nexus pods
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// Properties written to the server configuration file ("/nexus-data/etc/nexus.properties"), such as "nexus.scripts.allowCreation".
+	// The file is replaced by these properties whenever the server starts and changing them triggers a new rollout.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	Properties map[string]string `json:"properties,omitempty"`
+
+	// DeploymentStrategy describes how to replace the Nexus pods with new ones.
+	// Defaults to "Recreate" for persistent instances with a single replica, since the new pod can't attach the volume
+	// while the old one holds it. Defaults to "RollingUpdate" otherwise.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	DeploymentStrategy v1.DeploymentStrategy `json:"deploymentStrategy,omitempty"`
+
+	// ImagePullSecrets are references to Secrets in the same namespace used to pull the Nexus image.
+	// They are also added to the Service Account created by the Operator and used to check for automatic updates.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// TrustedCAs reference PEM-encoded CA bundles trusted by the server in addition to the JVM default ones, such as the CA
+	// issuing the certificates of proxied internal repositories. An init container builds a truststore from them and the server
+	// JVM is configured to use it. Updating the bundles rolls out a new pod.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	TrustedCAs []NexusCABundleSource `json:"trustedCAs,omitempty"`
+
+	// SecurityContext holds the pod-level security attributes. The fields set here override the defaults: when using the
+	// community image, the pod runs with the user, the group and the file system group 200, which owns the data directory.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+
+	// ContainerSecurityContext holds the security attributes of the Nexus container and of the init containers created by the Operator,
+	// such as "readOnlyRootFilesystem" or the capabilities to drop.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty"`
+
+	// WorkloadType is the kind of object running the Nexus pods, either "Deployment" or "StatefulSet".
+	// A StatefulSet is only used for persistent instances, its volume is created from a claim template instead of a separate PVC.
+	// Defaults to "Deployment".
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	WorkloadType NexusWorkloadType `json:"workloadType,omitempty"`
+
+	// DisruptionBudget configures the PodDisruptionBudget protecting the Nexus pods from voluntary disruptions, such as node drains.
+	// Defaults to keeping at least one pod available.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	DisruptionBudget NexusDisruptionBudget `json:"disruptionBudget,omitempty"`
+
+	// TerminationGracePeriodSeconds is how long the server has to shut down before being killed, giving it time to flush its databases.
+	// Defaults to 120.
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// FreezeOnShutdown adds a preStop hook putting the server in read-only mode before it's stopped. The hook authenticates as
+	// the operator user, so server operations must be able to create it. The Operator releases the freeze once the server is back,
+	// as long as it was the one stopping the pods.
+	// Defaults to `false`.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	FreezeOnShutdown bool `json:"freezeOnShutdown,omitempty"`
+
+	// Maintenance switches the instance into maintenance mode: the server is put in read-only mode once its pending writes are done.
+	// Setting it back to `false` releases the server. Defaults to `false`.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	Maintenance bool `json:"maintenance,omitempty"`
+
+	// ScaleDownOnMaintenance scales the instance down to zero replicas once the server has been frozen for maintenance,
+	// such as before migrating its storage. The replicas are scaled back up when the maintenance is over. Defaults to `false`.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	ScaleDownOnMaintenance bool `json:"scaleDownOnMaintenance,omitempty"`
+
+	// HighAvailability configures Nexus Repository Pro to run as a cluster, the only way to have more than one replica.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	HighAvailability NexusHighAvailability `json:"highAvailability,omitempty"`
+
+	// ExtraPorts are opened in the Nexus container besides the 8081 one, such as the HTTP connectors of Docker repositories.
+	// They're also added to the Service and, when a host is informed, exposed by the Ingress or Route.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=false
+	// +optional
+	ExtraPorts []NexusPort `json:"extraPorts,omitempty"`
+}
+
+// NexusPort describes an additional port served by Nexus
+type NexusPort struct {
+	// Name of the port, used in the container and in the Service. Must be a lowercase IANA service name of up to 15 characters other than "http".
+	Name string `json:"name"`
+	// Port number, used both in the container and in the Service.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+	// Protocol of the port: "TCP", "UDP" or "SCTP". Defaults to "TCP".
+	// +kubebuilder:validation:Enum=TCP;UDP;SCTP
+	// +optional
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+	// Host where the port is exposed when `spec.networking.expose` is `true` and the instance is exposed as an Ingress or a Route.
+	// Only TCP ports serving HTTP can be exposed. Left blank, the port is only reachable through the Service.
+	// +optional
+	Host string `json:"host,omitempty"`
+}
+
+// NexusHighAvailability describes how the Nexus Repository Pro nodes work together
+type NexusHighAvailability struct {
+	// Mode is the Nexus Repository Pro high availability mode, which requires a Pro license installed in the server.
+	// "HA-C" (High Availability Clustering) has every node sharing the data volume, so the storage class must support "ReadWriteMany".
+	// It requires persistence and the "Deployment" workload type.
+	// Leave it blank for Nexus OSS, limited to a single replica.
+	// +kubebuilder:validation:Enum=HA-C
+	// +optional
+	Mode NexusHighAvailabilityMode `json:"mode,omitempty"`
+}
+
+// NexusHighAvailabilityMode is the Nexus Repository Pro high availability mode
+type NexusHighAvailabilityMode string
+
+const (
+	// ClusteringHighAvailabilityMode runs the nodes as a Nexus Repository Pro cluster sharing the data volume (HA-C)
+	ClusteringHighAvailabilityMode NexusHighAvailabilityMode = "HA-C"
+)
+
+// NexusDisruptionBudget describes the PodDisruptionBudget created for the Nexus pods when running more than one replica.
+// Only one of "minAvailable" and "maxUnavailable" can be set.
+type NexusDisruptionBudget struct {
+	// Disabled skips the creation of the PodDisruptionBudget. Defaults to `false`.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+	// MinAvailable is the number or percentage of pods that must remain available during a disruption. Defaults to 1.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// MaxUnavailable is the number or percentage of pods that can be unavailable during a disruption.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// NexusWorkloadType is the kind of object running the Nexus pods
+type NexusWorkloadType string
+
+const (
+	// DeploymentWorkloadType runs the Nexus pods with a Deployment, using a separate PVC when persistent
+	DeploymentWorkloadType NexusWorkloadType = "Deployment"
+	// StatefulSetWorkloadType runs the Nexus pods with a StatefulSet, using a volume claim template when persistent
+	StatefulSetWorkloadType NexusWorkloadType = "StatefulSet"
+)
+
+// NexusJVMGarbageCollector is the garbage collector used by the Nexus server JVM
+type NexusJVMGarbageCollector string
+
+const (
+	// G1GarbageCollector enables the Garbage-First collector (`-XX:+UseG1GC`)
+	G1GarbageCollector NexusJVMGarbageCollector = "G1"
+	// ParallelGarbageCollector enables the parallel collector (`-XX:+UseParallelGC`)
+	ParallelGarbageCollector NexusJVMGarbageCollector = "Parallel"
+	// SerialGarbageCollector enables the serial collector (`-XX:+UseSerialGC`)
+	SerialGarbageCollector NexusJVMGarbageCollector = "Serial"
+)
+
+// NexusJVM describes the options used to tune the Nexus server JVM
+type NexusJVM struct {
+	// HeapPercentage is the percentage of the container memory limit used for the heap size (`-Xms` and `-Xmx`).
+	// Defaults to 80.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	HeapPercentage int32 `json:"heapPercentage,omitempty"`
+	// MaxDirectMemorySize is the value for `-XX:MaxDirectMemorySize`, such as "2048m". Defaults to the container memory limit.
+	// +optional
+	MaxDirectMemorySize string `json:"maxDirectMemorySize,omitempty"`
+	// GarbageCollector is the garbage collector used by the JVM: G1, Parallel or Serial. If left blank, the JVM default is used.
+	// Ignored when the extra args select a collector themselves.
+	// +kubebuilder:validation:Enum=G1;Parallel;Serial
+	// +optional
+	GarbageCollector NexusJVMGarbageCollector `json:"garbageCollector,omitempty"`
+	// ExtraArgs are additional `-D` or `-XX` options passed to the JVM, such as "-Dnexus.licenseFile=/nexus-data/license.lic".
+	// Options also set by the Operator are overridden by the ones informed here.
+	// +optional
+	ExtraArgs []string `json:"extraArgs,omitempty"`
 }
 
 // NexusPersistence is the structure for the data persistent
@@ -157,7 +437,7 @@ type NexusNetworking struct {
 // +k8s:openapi-gen=true
 type NexusProbe struct {
 	// Number of seconds after the container has started before probes are initiated.
-	// Defaults to 240 seconds. Minimum value is 0.
+	// Defaults to 0 seconds. Minimum value is 0.
 	// +optional
 	// +kubebuilder:validation:Minimum=0
 	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty" protobuf:"varint,2,opt,name=initialDelaySeconds"`
@@ -177,7 +457,7 @@ type NexusProbe struct {
 	// +kubebuilder:validation:Minimum=1
 	SuccessThreshold int32 `json:"successThreshold,omitempty" protobuf:"varint,5,opt,name=successThreshold"`
 	// Minimum consecutive failures for the probe to be considered failed after having succeeded.
-	// Defaults to 3. Minimum value is 1.
+	// Defaults to 3, or to 60 for startup. Minimum value is 1.
 	// +optional
 	// +kubebuilder:validation:Minimum=1
 	FailureThreshold int32 `json:"failureThreshold,omitempty" protobuf:"varint,6,opt,name=failureThreshold"`
@@ -203,6 +483,38 @@ type ServerOperationsOpts struct {
 	// all the operations on the server (such as creating the community repos). If disabled, the Operator will use the default `admin` user.
 	// Defaults to `false` (always create the user). Setting this to `true` is not recommended as it grants the Operator more privileges than it needs and it would not be possible to tell apart operations performed by the `admin` and the Operator.
 	DisableOperatorUserCreation bool `json:"disableOperatorUserCreation,omitempty"`
+	// ResyncPeriodSeconds is the interval in seconds in which the Operator performs the server operations again even if their configuration hasn't changed.
+	// Defaults to 600 (10 minutes).
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ResyncPeriodSeconds int32 `json:"resyncPeriodSeconds,omitempty"`
+	// TLS describes how the Operator reaches the Nexus server when it serves HTTPS
+	// +optional
+	TLS ServerOperationsTLS `json:"tls,omitempty"`
+	// ExposeDockerConnectors adds the HTTP connector ports of the Docker repositories found in the server to the Service,
+	// as if they were informed in `spec.extraPorts`. Defaults to `false`.
+	// +optional
+	ExposeDockerConnectors bool `json:"exposeDockerConnectors,omitempty"`
+}
+
+// ServerOperationsTLS describes the TLS configuration used by the Operator to reach the Nexus server
+type ServerOperationsTLS struct {
+	// Set to `true` if the Nexus server serves HTTPS on its service port. Defaults to `false`.
+	Enabled bool `json:"enabled,omitempty"`
+	// CABundle references the PEM-encoded CA bundle used to verify the server certificate. If not set, the system trust store is used.
+	// +optional
+	CABundle *NexusCABundleSource `json:"caBundle,omitempty"`
+}
+
+// NexusCABundleSource references a PEM-encoded CA bundle stored in a Secret or in a ConfigMap in the same namespace as the Nexus CR.
+// Only one of them may be set.
+type NexusCABundleSource struct {
+	// Selects a key of a Secret
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+	// Selects a key of a ConfigMap
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
 }
 
 // NexusAutomaticUpdate defines configuration for automatic updates
@@ -220,11 +532,11 @@ type NexusAutomaticUpdate struct {
 // NexusStatus defines the observed state of Nexus
 // +k8s:openapi-gen=true
 type NexusStatus struct {
-	// Condition status for the Nexus deployment
+	// Condition status for the Nexus deployment. The replica counts of the StatefulSet are reported here when running as one.
 	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
 	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="appsv1.DeploymentStatus"
 	DeploymentStatus v1.DeploymentStatus `json:"deploymentStatus,omitempty"`
-	// Will be "OK" when this Nexus instance is up
+	// Will be "OK" when this Nexus instance is up and "Maintenance" while it is frozen for maintenance
 	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
 	NexusStatus NexusStatusType `json:"nexusStatus,omitempty"`
 	// Gives more information about a failure status
@@ -240,6 +552,17 @@ type NexusStatus struct {
 	UpdateConditions []string `json:"updateConditions,omitempty"`
 	// ServerOperationsStatus describes the general status for the operations performed in the Nexus server instance
 	ServerOperationsStatus OperationsStatus `json:"serverOperationsStatus,omitempty"`
+	// Conditions reported by the Nexus server status API, including each one of its system checks
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Conditions"
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	Conditions status.Conditions `json:"conditions,omitempty"`
+	// MaintenanceFrozen is set while the server is in the read-only mode requested by the Operator because of 'spec.maintenance'
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	MaintenanceFrozen bool `json:"maintenanceFrozen,omitempty"`
+	// ShutdownFrozen is set when the Operator rolled out the pods with 'spec.freezeOnShutdown' enabled, so the server may have been left
+	// in read-only mode by the preStop hook. The Operator only releases read-only modes recorded here.
+	ShutdownFrozen bool `json:"shutdownFrozen,omitempty"`
 }
 
 // OperationsStatus describes the status for each operation made by the operator in the deployed Nexus Server
@@ -249,6 +572,16 @@ type OperationsStatus struct {
 	CommunityRepositoriesCreated bool   `json:"communityRepositoriesCreated,omitempty"`
 	MavenCentralUpdated          bool   `json:"mavenCentralUpdated,omitempty"`
 	Reason                       string `json:"reason,omitempty"`
+	// ConfigHash is the fingerprint of the server-side configuration last applied successfully
+	ConfigHash string `json:"configHash,omitempty"`
+	// LastAppliedTime is when the server-side configuration was last applied successfully
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+	// Attempts is the number of consecutive times the Operator tried to perform the pending server operations
+	Attempts int32 `json:"attempts,omitempty"`
+	// NextRetryTime is when the Operator will try to perform the pending server operations again
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+	// DockerConnectorPorts are the HTTP connector ports of the Docker repositories found in the server when `spec.serverOperations.exposeDockerConnectors` is `true`
+	DockerConnectorPorts []NexusPort `json:"dockerConnectorPorts,omitempty"`
 }
 
 type NexusStatusType string
@@ -257,6 +590,8 @@ const (
 	NexusStatusOK      NexusStatusType = "OK"
 	NexusStatusFailure NexusStatusType = "Failure"
 	NexusStatusPending NexusStatusType = "Pending"
+	// NexusStatusMaintenance is reported while the server is frozen for maintenance
+	NexusStatusMaintenance NexusStatusType = "Maintenance"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
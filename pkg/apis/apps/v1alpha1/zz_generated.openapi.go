@@ -122,7 +122,7 @@ func schema_pkg_apis_apps_v1alpha1_NexusProbe(ref common.ReferenceCallback) comm
 				Properties: map[string]spec.Schema{
 					"initialDelaySeconds": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Number of seconds after the container has started before probes are initiated. Defaults to 240 seconds. Minimum value is 0.",
+							Description: "Number of seconds after the container has started before probes are initiated. Defaults to 0 seconds. Minimum value is 0.",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
@@ -150,7 +150,7 @@ func schema_pkg_apis_apps_v1alpha1_NexusProbe(ref common.ReferenceCallback) comm
 					},
 					"failureThreshold": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3. Minimum value is 1.",
+							Description: "Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3, or to 60 for startup. Minimum value is 1.",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
@@ -170,7 +170,7 @@ func schema_pkg_apis_apps_v1alpha1_NexusSpec(ref common.ReferenceCallback) commo
 				Properties: map[string]spec.Schema{
 					"replicas": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Number of pod replicas desired. Defaults to 0.",
+							Description: "Number of pod replicas desired. Defaults to 0. Nexus OSS can't run several nodes against one data directory, so more than one replica requires `spec.highAvailability.mode`.",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
@@ -246,18 +246,341 @@ func schema_pkg_apis_apps_v1alpha1_NexusSpec(ref common.ReferenceCallback) commo
 							Ref:         ref("./pkg/apis/apps/v1alpha1.NexusProbe"),
 						},
 					},
+					"startupProbe": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartupProbe describes how the Nexus container startup probe should work. Liveness and readiness probes are only performed after it succeeds, so it should cover the time the server takes to start.",
+							Ref:         ref("./pkg/apis/apps/v1alpha1.NexusProbe"),
+						},
+					},
 					"serverOperations": {
 						SchemaProps: spec.SchemaProps{
 							Description: "ServerOperations describes the options for the operations performed on the deployed server instance",
 							Ref:         ref("./pkg/apis/apps/v1alpha1.ServerOperationsOpts"),
 						},
 					},
+					"jvm": {
+						SchemaProps: spec.SchemaProps{
+							Description: "JVM describes how the Nexus server JVM should be tuned",
+							Ref:         ref("./pkg/apis/apps/v1alpha1.NexusJVM"),
+						},
+					},
+					"env": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Env is a list of additional environment variables to set in the Nexus container",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
+						},
+					},
+					"envFrom": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EnvFrom is a list of sources to populate environment variables in the Nexus container",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.EnvFromSource"),
+									},
+								},
+							},
+						},
+					},
+					"volumes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Volumes is a list of additional volumes that can be mounted by the Nexus container",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Volume"),
+									},
+								},
+							},
+						},
+					},
+					"volumeMounts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "VolumeMounts is a list of additional volumes to mount into the Nexus container's filesystem",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.VolumeMount"),
+									},
+								},
+							},
+						},
+					},
+					"sidecars": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Sidecars is a list of additional containers to run alongside the Nexus container in the same pod, such as a log shipper",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
+						},
+					},
+					"initContainers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "InitContainers is a list of containers to run before the Nexus container is started, such as one fixing volume permissions",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Container"),
+									},
+								},
+							},
+						},
+					},
+					"nodeSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeSelector is a selector which must match a node's labels for the Nexus pod to be scheduled on that node",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"tolerations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tolerations allow the Nexus pod to be scheduled on nodes with matching taints",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
+						},
+					},
+					"affinity": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Affinity describes the scheduling constraints of the Nexus pod",
+							Ref:         ref("k8s.io/api/core/v1.Affinity"),
+						},
+					},
+					"topologySpreadConstraints": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TopologySpreadConstraints describes how the Nexus pods ought to spread across topology domains",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.TopologySpreadConstraint"),
+									},
+								},
+							},
+						},
+					},
+					"priorityClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PriorityClassName is the name of the PriorityClass of the Nexus pod",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"labels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Labels added to every resource created by the Operator for this instance. The labels used by the Operator to select the Nexus pods can't be overridden.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"annotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Annotations added to every resource created by the Operator for this instance",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"podLabels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodLabels added to the Nexus pods. The labels used by the Operator to select the Nexus pods can't be overridden.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"podAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodAnnotations added to the Nexus pods",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"properties": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Properties written to the server configuration file (\"/nexus-data/etc/nexus.properties\"), such as \"nexus.scripts.allowCreation\". The file is replaced by these properties whenever the server starts and changing them triggers a new rollout.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"deploymentStrategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeploymentStrategy describes how to replace the Nexus pods with new ones. Defaults to \"Recreate\" for persistent instances with a single replica, since the new pod can't attach the volume while the old one holds it. Defaults to \"RollingUpdate\" otherwise.",
+							Ref:         ref("k8s.io/api/apps/v1.DeploymentStrategy"),
+						},
+					},
+					"imagePullSecrets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImagePullSecrets are references to Secrets in the same namespace used to pull the Nexus image. They are also added to the Service Account created by the Operator and used to check for automatic updates.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
+									},
+								},
+							},
+						},
+					},
+					"trustedCAs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TrustedCAs reference PEM-encoded CA bundles trusted by the server in addition to the JVM default ones, such as the CA issuing the certificates of proxied internal repositories. An init container builds a truststore from them and the server JVM is configured to use it. Updating the bundles rolls out a new pod.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("./pkg/apis/apps/v1alpha1.NexusCABundleSource"),
+									},
+								},
+							},
+						},
+					},
+					"securityContext": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecurityContext holds the pod-level security attributes. The fields set here override the defaults: when using the community image, the pod runs with the user, the group and the file system group 200, which owns the data directory.",
+							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
+						},
+					},
+					"containerSecurityContext": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ContainerSecurityContext holds the security attributes of the Nexus container and of the init containers created by the Operator, such as \"readOnlyRootFilesystem\" or the capabilities to drop.",
+							Ref:         ref("k8s.io/api/core/v1.SecurityContext"),
+						},
+					},
+					"workloadType": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WorkloadType is the kind of object running the Nexus pods, either \"Deployment\" or \"StatefulSet\". A StatefulSet is only used for persistent instances, its volume is created from a claim template instead of a separate PVC. Defaults to \"Deployment\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"disruptionBudget": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisruptionBudget configures the PodDisruptionBudget protecting the Nexus pods from voluntary disruptions, such as node drains. Defaults to keeping at least one pod available.",
+							Ref:         ref("./pkg/apis/apps/v1alpha1.NexusDisruptionBudget"),
+						},
+					},
+					"terminationGracePeriodSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TerminationGracePeriodSeconds is how long the server has to shut down before being killed, giving it time to flush its databases. Defaults to 120.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"freezeOnShutdown": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FreezeOnShutdown adds a preStop hook putting the server in read-only mode before it's stopped. The hook authenticates as the operator user, so server operations must be able to create it. The Operator releases the freeze once the server is back, as long as it was the one stopping the pods. Defaults to `false`.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"maintenance": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Maintenance switches the instance into maintenance mode: the server is put in read-only mode once its pending writes are done. Setting it back to `false` releases the server. Defaults to `false`.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"scaleDownOnMaintenance": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ScaleDownOnMaintenance scales the instance down to zero replicas once the server has been frozen for maintenance, such as before migrating its storage. The replicas are scaled back up when the maintenance is over. Defaults to `false`.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"highAvailability": {
+						SchemaProps: spec.SchemaProps{
+							Description: "HighAvailability configures Nexus Repository Pro to run as a cluster, the only way to have more than one replica.",
+							Ref:         ref("./pkg/apis/apps/v1alpha1.NexusHighAvailability"),
+						},
+					},
+					"extraPorts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExtraPorts are opened in the Nexus container besides the 8081 one, such as the HTTP connectors of Docker repositories. They're also added to the Service and, when a host is informed, exposed by the Ingress or Route.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("./pkg/apis/apps/v1alpha1.NexusPort"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"replicas", "persistence", "useRedHatImage"},
 			},
 		},
 		Dependencies: []string{
-			"./pkg/apis/apps/v1alpha1.NexusAutomaticUpdate", "./pkg/apis/apps/v1alpha1.NexusNetworking", "./pkg/apis/apps/v1alpha1.NexusPersistence", "./pkg/apis/apps/v1alpha1.NexusProbe", "./pkg/apis/apps/v1alpha1.ServerOperationsOpts", "k8s.io/api/core/v1.ResourceRequirements"},
+			"./pkg/apis/apps/v1alpha1.NexusAutomaticUpdate", "./pkg/apis/apps/v1alpha1.NexusCABundleSource", "./pkg/apis/apps/v1alpha1.NexusDisruptionBudget", "./pkg/apis/apps/v1alpha1.NexusHighAvailability", "./pkg/apis/apps/v1alpha1.NexusJVM", "./pkg/apis/apps/v1alpha1.NexusNetworking", "./pkg/apis/apps/v1alpha1.NexusPersistence", "./pkg/apis/apps/v1alpha1.NexusPort", "./pkg/apis/apps/v1alpha1.NexusProbe", "./pkg/apis/apps/v1alpha1.ServerOperationsOpts", "k8s.io/api/apps/v1.DeploymentStrategy", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvFromSource", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.SecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.TopologySpreadConstraint", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
 	}
 }
 
@@ -270,13 +593,13 @@ func schema_pkg_apis_apps_v1alpha1_NexusStatus(ref common.ReferenceCallback) com
 				Properties: map[string]spec.Schema{
 					"deploymentStatus": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Condition status for the Nexus deployment",
+							Description: "Condition status for the Nexus deployment. The replica counts of the StatefulSet are reported here when running as one.",
 							Ref:         ref("k8s.io/api/apps/v1.DeploymentStatus"),
 						},
 					},
 					"nexusStatus": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Will be \"OK\" when this Nexus instance is up",
+							Description: "Will be \"OK\" when this Nexus instance is up and \"Maintenance\" while it is frozen for maintenance",
 							Type:        []string{"string"},
 							Format:      "",
 						},
@@ -320,10 +643,37 @@ func schema_pkg_apis_apps_v1alpha1_NexusStatus(ref common.ReferenceCallback) com
 							Ref:         ref("./pkg/apis/apps/v1alpha1.OperationsStatus"),
 						},
 					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Conditions reported by the Nexus server status API, including each one of its system checks",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/operator-framework/operator-sdk/pkg/status.Condition"),
+									},
+								},
+							},
+						},
+					},
+					"maintenanceFrozen": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaintenanceFrozen is set while the server is in the read-only mode requested by the Operator because of 'spec.maintenance'",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"shutdownFrozen": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ShutdownFrozen is set when the Operator rolled out the pods with 'spec.freezeOnShutdown' enabled, so the server may have been left in read-only mode by the preStop hook. The Operator only releases read-only modes recorded here.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"./pkg/apis/apps/v1alpha1.OperationsStatus", "k8s.io/api/apps/v1.DeploymentStatus"},
+			"./pkg/apis/apps/v1alpha1.OperationsStatus", "github.com/operator-framework/operator-sdk/pkg/status.Condition", "k8s.io/api/apps/v1.DeploymentStatus"},
 	}
 }
@@ -30,6 +30,7 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 
 	resUtils "github.com/RHsyseng/operator-utils/pkg/resource"
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
 	"github.com/RHsyseng/operator-utils/pkg/resource/write"
 
 	appsv1alpha1 "github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
@@ -38,6 +39,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -102,7 +104,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 			AddToScheme:  networking.AddToScheme,
 			Objects:      []runtime.Object{&networking.Ingress{}},
 		},
-		{Objects: []runtime.Object{&corev1.Service{}, &appsv1.Deployment{}, &corev1.PersistentVolumeClaim{}, &corev1.ServiceAccount{}}},
+		{Objects: []runtime.Object{&corev1.Service{}, &appsv1.Deployment{}, &appsv1.StatefulSet{}, &corev1.PersistentVolumeClaim{}, &corev1.ServiceAccount{}, &corev1.ConfigMap{}, &policyv1beta1.PodDisruptionBudget{}}},
+		// ConfigMaps and Secrets referenced by the pods, usually not owned by the instance
+		{
+			Objects: []runtime.Object{&corev1.ConfigMap{}, &corev1.Secret{}},
+			Handler: &handler.EnqueueRequestsFromMapFunc{ToRequests: referencingNexusMapper(mgr.GetClient())},
+		},
 	}
 	if err = controllerWatcher.Watch(watchedObjects...); err != nil {
 		return err
@@ -203,31 +210,69 @@ func (r *ReconcileNexus) Reconcile(request reconcile.Request) (result reconcile.
 		if err != nil {
 			return
 		}
+		recordShutdownFreeze(validatedNexus, resourceType, delta)
+	}
+
+	if err = r.ensureMaintenance(validatedNexus, &result); err != nil {
+		return
+	}
+	if validatedNexus.Spec.Maintenance {
+		// the server is (or is about to be) frozen, no point in changing it now
+		return
 	}
 
 	if err = r.ensureServerUpdates(validatedNexus); err != nil {
 		return
 	}
+	// server operations are still pending or must be resynced, try again later
+	result.RequeueAfter = server.RequeueAfter(validatedNexus)
 
 	// Check if we are performing an update and act upon it if needed
 	err = r.handleUpdate(validatedNexus, requiredRes, deployedRes)
 	return
 }
 
+// recordShutdownFreeze flags the server as possibly frozen by the preStop hook when the workload pods are rolled out or removed,
+// so it's released once the server is back. Read-only modes set by anyone else are left alone.
+func recordShutdownFreeze(nexus *appsv1alpha1.Nexus, resourceType reflect.Type, delta compare.ResourceDelta) {
+	if !nexus.Spec.FreezeOnShutdown || (len(delta.Updated) == 0 && len(delta.Removed) == 0) {
+		return
+	}
+	if resourceType == reflect.TypeOf(appsv1.Deployment{}) || resourceType == reflect.TypeOf(appsv1.StatefulSet{}) {
+		nexus.Status.ShutdownFrozen = true
+	}
+}
+
 func (r *ReconcileNexus) handleUpdate(nexus *appsv1alpha1.Nexus, required, deployed map[reflect.Type][]resUtils.KubernetesResource) error {
-	requiredDeployment := required[reflect.TypeOf(appsv1.Deployment{})][0].(*appsv1.Deployment)
-	deployedDeployments := deployed[reflect.TypeOf(appsv1.Deployment{})]
-	if len(deployedDeployments) == 0 {
+	workloadType := reflect.TypeOf(appsv1.Deployment{})
+	if nexus.Spec.WorkloadType == appsv1alpha1.StatefulSetWorkloadType {
+		workloadType = reflect.TypeOf(appsv1.StatefulSet{})
+	}
+	deployedWorkloads := deployed[workloadType]
+	if len(deployedWorkloads) == 0 {
 		// nothing previously deployed, not an update
 		return nil
 	}
-	deployedDeployment := deployedDeployments[0].(*appsv1.Deployment)
-	return update.HandleUpdate(nexus, deployedDeployment, requiredDeployment, r.scheme, r.client)
+	return update.HandleUpdate(nexus, deployedWorkloads[0], required[workloadType][0], r.scheme, r.client)
+}
+
+// ensureMaintenance freezes or releases the server according to the maintenance mode,
+// requeueing once it changes so the workload can be scaled accordingly
+func (r *ReconcileNexus) ensureMaintenance(instance *appsv1alpha1.Nexus, result *reconcile.Result) error {
+	frozen, err := server.HandleMaintenance(instance, r.client, r.scheme)
+	if err != nil {
+		return err
+	}
+	if frozen != instance.Status.MaintenanceFrozen {
+		instance.Status.MaintenanceFrozen = frozen
+		result.Requeue = true
+	}
+	return nil
 }
 
 func (r *ReconcileNexus) ensureServerUpdates(instance *appsv1alpha1.Nexus) error {
 	log.Info("Performing Nexus server operations if needed")
-	status, err := server.HandleServerOperations(instance, r.client)
+	status, err := server.HandleServerOperations(instance, r.client, r.scheme)
 	if err != nil {
 		return err
 	}
@@ -248,7 +293,9 @@ func (r *ReconcileNexus) updateNexus(nexus *appsv1alpha1.Nexus, originalNexus *a
 		nexus.Status.NexusStatus = appsv1alpha1.NexusStatusFailure
 	} else {
 		nexus.Status.Reason = ""
-		if nexus.Status.DeploymentStatus.AvailableReplicas == nexus.Spec.Replicas {
+		if nexus.Spec.Maintenance && nexus.Status.MaintenanceFrozen {
+			nexus.Status.NexusStatus = appsv1alpha1.NexusStatusMaintenance
+		} else if nexus.Status.DeploymentStatus.AvailableReplicas == nexus.Spec.Replicas {
 			nexus.Status.NexusStatus = appsv1alpha1.NexusStatusOK
 		} else {
 			nexus.Status.NexusStatus = appsv1alpha1.NexusStatusPending
@@ -313,6 +360,9 @@ func (r *ReconcileNexus) updateNexus(nexus *appsv1alpha1.Nexus, originalNexus *a
 }
 
 func (r *ReconcileNexus) getNexusDeploymentStatus(nexus *appsv1alpha1.Nexus) error {
+	if nexus.Spec.WorkloadType == appsv1alpha1.StatefulSetWorkloadType {
+		return r.getNexusStatefulSetStatus(nexus)
+	}
 	log.Info("Checking Deployment Status")
 	deployment := &appsv1.Deployment{}
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: nexus.Namespace, Name: nexus.Name}, deployment); err != nil {
@@ -324,6 +374,27 @@ func (r *ReconcileNexus) getNexusDeploymentStatus(nexus *appsv1alpha1.Nexus) err
 	return nil
 }
 
+// getNexusStatefulSetStatus reports the StatefulSet replicas in the Deployment status, so clients don't need to care about the workload type
+func (r *ReconcileNexus) getNexusStatefulSetStatus(nexus *appsv1alpha1.Nexus) error {
+	log.Info("Checking StatefulSet Status")
+	statefulSet := &appsv1.StatefulSet{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: nexus.Namespace, Name: nexus.Name}, statefulSet); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	nexus.Status.DeploymentStatus = appsv1.DeploymentStatus{
+		ObservedGeneration: statefulSet.Status.ObservedGeneration,
+		Replicas:           statefulSet.Status.Replicas,
+		UpdatedReplicas:    statefulSet.Status.UpdatedReplicas,
+		ReadyReplicas:      statefulSet.Status.ReadyReplicas,
+		// StatefulSets in this API version don't track availability, ready pods are the closest we have
+		AvailableReplicas:   statefulSet.Status.ReadyReplicas,
+		UnavailableReplicas: statefulSet.Status.Replicas - statefulSet.Status.ReadyReplicas,
+	}
+	return nil
+}
+
 func (r *ReconcileNexus) getNexusURL(nexus *appsv1alpha1.Nexus) error {
 	if nexus.Spec.Networking.Expose {
 		var err error
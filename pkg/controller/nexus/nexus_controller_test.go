@@ -18,8 +18,10 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	resUtils "github.com/RHsyseng/operator-utils/pkg/resource"
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
 	nexusres "github.com/m88i/nexus-operator/pkg/controller/nexus/resource"
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/validation"
@@ -29,6 +31,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -83,6 +86,8 @@ func TestReconcileNexus_Reconcile_NotPersistent(t *testing.T) {
 	res, err := r.Reconcile(req)
 	assert.NoError(t, err)
 	assert.False(t, res.Requeue)
+	// server isn't ready yet, so we must try again later
+	assert.True(t, res.RequeueAfter > 0)
 	// let's check our replica
 	dep := &appsv1.Deployment{}
 	err = r.client.Get(context.TODO(), req.NamespacedName, dep)
@@ -99,17 +104,40 @@ func TestReconcileNexus_Reconcile_NotPersistent(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Nil(t, route.Spec.TLS)
 	assert.Equal(t, route.Spec.Port.TargetPort.IntVal, dep.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort)
+	// a single pod isn't protected from voluntary disruptions, otherwise drains would be blocked
+	budget := &policyv1beta1.PodDisruptionBudget{}
+	err = r.client.Get(context.TODO(), req.NamespacedName, budget)
+	assert.True(t, errors.IsNotFound(err))
 
 	err = r.client.Get(context.TODO(), req.NamespacedName, nexus)
 	assert.NoError(t, err)
 	assert.NotNil(t, nexus)
 	assert.False(t, nexus.Status.ServerOperationsStatus.ServerReady)
 	assert.NotEmpty(t, nexus.Status.ServerOperationsStatus.Reason)
+	assert.Equal(t, int32(1), nexus.Status.ServerOperationsStatus.Attempts)
+	assert.NotNil(t, nexus.Status.ServerOperationsStatus.NextRetryTime)
 
-	// a second attempt must not requeue and not fail
+	// reconciling again before the scheduled retry must not count as another attempt
+	firstRequeueAfter := res.RequeueAfter
 	res, err = r.Reconcile(req)
 	assert.NoError(t, err)
 	assert.False(t, res.Requeue)
+	assert.True(t, res.RequeueAfter > 0 && res.RequeueAfter <= firstRequeueAfter)
+	err = r.client.Get(context.TODO(), req.NamespacedName, nexus)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), nexus.Status.ServerOperationsStatus.Attempts)
+
+	// once the retry is due, a second attempt must not requeue and not fail, but should back off a bit more
+	past := metav1.NewTime(time.Now().Add(-time.Second))
+	nexus.Status.ServerOperationsStatus.NextRetryTime = &past
+	assert.NoError(t, r.client.Status().Update(context.TODO(), nexus))
+	res, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.False(t, res.Requeue)
+	assert.True(t, res.RequeueAfter > firstRequeueAfter)
+	err = r.client.Get(context.TODO(), req.NamespacedName, nexus)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), nexus.Status.ServerOperationsStatus.Attempts)
 }
 
 func TestReconcileNexus_Reconcile_Persistent(t *testing.T) {
@@ -151,6 +179,137 @@ func TestReconcileNexus_Reconcile_Persistent(t *testing.T) {
 	assert.False(t, errors.IsNotFound(err))
 }
 
+func TestReconcileNexus_Reconcile_StatefulSet(t *testing.T) {
+	ns := t.Name()
+	appName := "nexus3"
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: appName},
+		Spec: v1alpha1.NexusSpec{
+			Replicas:         1,
+			WorkloadType:     v1alpha1.StatefulSetWorkloadType,
+			Persistence:      v1alpha1.NexusPersistence{Persistent: true},
+			ServerOperations: v1alpha1.ServerOperationsOpts{DisableOperatorUserCreation: true, DisableRepositoryCreation: true},
+		},
+	}
+
+	// create objects to run reconcile
+	cl := test.NewFakeClientBuilder(nexus).Build()
+	r := newFakeReconcileNexus(cl)
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{
+		Namespace: ns,
+		Name:      appName,
+	}}
+
+	// reconcile phase
+	res, err := r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.False(t, res.Requeue)
+	// the pods are run by a StatefulSet claiming its own volume
+	statefulSet := &appsv1.StatefulSet{}
+	assert.NoError(t, r.client.Get(context.TODO(), req.NamespacedName, statefulSet))
+	assert.Len(t, statefulSet.Spec.VolumeClaimTemplates, 1)
+	assert.True(t, errors.IsNotFound(r.client.Get(context.TODO(), req.NamespacedName, &appsv1.Deployment{})))
+	assert.True(t, errors.IsNotFound(r.client.Get(context.TODO(), req.NamespacedName, &corev1.PersistentVolumeClaim{})))
+	assert.NoError(t, r.client.Get(context.TODO(), req.NamespacedName, &corev1.Service{}))
+	// governed by a headless Service of its own
+	headless := &corev1.Service{}
+	assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: statefulSet.Spec.ServiceName}, headless))
+	assert.Equal(t, corev1.ClusterIPNone, headless.Spec.ClusterIP)
+}
+
+func TestReconcileNexus_Reconcile_Maintenance(t *testing.T) {
+	ns := t.Name()
+	appName := "nexus3"
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: appName},
+		Spec: v1alpha1.NexusSpec{
+			Replicas:               1,
+			Maintenance:            true,
+			ScaleDownOnMaintenance: true,
+			ServerOperations:       v1alpha1.ServerOperationsOpts{DisableOperatorUserCreation: true, DisableRepositoryCreation: true},
+		},
+		// frozen in a previous reconcile
+		Status: v1alpha1.NexusStatus{MaintenanceFrozen: true},
+	}
+
+	// create objects to run reconcile
+	cl := test.NewFakeClientBuilder(nexus).Build()
+	r := newFakeReconcileNexus(cl)
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{
+		Namespace: ns,
+		Name:      appName,
+	}}
+
+	// reconcile phase
+	_, err := r.Reconcile(req)
+	assert.NoError(t, err)
+	// scaled down while frozen
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.client.Get(context.TODO(), req.NamespacedName, deployment))
+	assert.Equal(t, int32(0), *deployment.Spec.Replicas)
+	assert.NoError(t, r.client.Get(context.TODO(), req.NamespacedName, nexus))
+	assert.Equal(t, v1alpha1.NexusStatusMaintenance, nexus.Status.NexusStatus)
+	assert.True(t, nexus.Status.MaintenanceFrozen)
+}
+
+func TestReconcileNexus_Reconcile_ExtraPorts(t *testing.T) {
+	ns := t.Name()
+	appName := "nexus3"
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: appName},
+		Spec: v1alpha1.NexusSpec{
+			Replicas:         1,
+			ServerOperations: v1alpha1.ServerOperationsOpts{DisableOperatorUserCreation: true, DisableRepositoryCreation: true},
+			Networking:       v1alpha1.NexusNetworking{Expose: true, ExposeAs: v1alpha1.IngressExposeType, Host: "nexus.example.com"},
+			ExtraPorts:       []v1alpha1.NexusPort{{Name: "docker", Port: 8082, Host: "docker.example.com"}},
+		},
+	}
+
+	// create objects to run reconcile
+	cl := test.NewFakeClientBuilder(nexus).WithIngress().Build()
+	r := newFakeReconcileNexus(cl)
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{
+		Namespace: ns,
+		Name:      appName,
+	}}
+
+	// reconcile phase
+	_, err := r.Reconcile(req)
+	assert.NoError(t, err)
+	// the port is served by the container, the Service and the Ingress
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.client.Get(context.TODO(), req.NamespacedName, deployment))
+	assert.Len(t, deployment.Spec.Template.Spec.Containers[0].Ports, 2)
+	svc := &corev1.Service{}
+	assert.NoError(t, r.client.Get(context.TODO(), req.NamespacedName, svc))
+	assert.Len(t, svc.Spec.Ports, 2)
+	ingress := &v1beta1.Ingress{}
+	assert.NoError(t, r.client.Get(context.TODO(), req.NamespacedName, ingress))
+	assert.Len(t, ingress.Spec.Rules, 2)
+}
+
+func Test_recordShutdownFreeze(t *testing.T) {
+	nexus := &v1alpha1.Nexus{Spec: v1alpha1.NexusSpec{FreezeOnShutdown: true}}
+	updated := compare.ResourceDelta{Updated: []resUtils.KubernetesResource{&appsv1.Deployment{}}}
+
+	// pods not affected
+	recordShutdownFreeze(nexus, reflect.TypeOf(corev1.Service{}), updated)
+	assert.False(t, nexus.Status.ShutdownFrozen)
+	recordShutdownFreeze(nexus, reflect.TypeOf(appsv1.Deployment{}), compare.ResourceDelta{Added: []resUtils.KubernetesResource{&appsv1.Deployment{}}})
+	assert.False(t, nexus.Status.ShutdownFrozen)
+
+	recordShutdownFreeze(nexus, reflect.TypeOf(appsv1.Deployment{}), updated)
+	assert.True(t, nexus.Status.ShutdownFrozen)
+
+	// no hook, nothing to record
+	nexus = &v1alpha1.Nexus{}
+	recordShutdownFreeze(nexus, reflect.TypeOf(appsv1.StatefulSet{}), compare.ResourceDelta{Removed: []resUtils.KubernetesResource{&appsv1.StatefulSet{}}})
+	assert.False(t, nexus.Status.ShutdownFrozen)
+}
+
 func Test_add(t *testing.T) {
 	cli := test.NewFakeClientBuilder().Build()
 	mgr := test.NewManager(cli)
@@ -189,6 +348,17 @@ func TestReconcileNexus_handleUpdate(t *testing.T) {
 	deployedDep := requiredDep.DeepCopy()
 	deployedRes[deploymentType] = []resUtils.KubernetesResource{deployedDep}
 	assert.NoError(t, r.handleUpdate(baseNexus, requiredRes, deployedRes))
+
+	// The StatefulSet is the one checked when running as such
+	statefulSetType := reflect.TypeOf(appsv1.StatefulSet{})
+	baseNexus.Spec.WorkloadType = v1alpha1.StatefulSetWorkloadType
+	requiredRes = map[reflect.Type][]resUtils.KubernetesResource{
+		statefulSetType: {&appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Template: requiredDep.Spec.Template}}},
+	}
+	deployedRes = map[reflect.Type][]resUtils.KubernetesResource{}
+	assert.NoError(t, r.handleUpdate(baseNexus, requiredRes, deployedRes))
+	deployedRes[statefulSetType] = []resUtils.KubernetesResource{requiredRes[statefulSetType][0].DeepCopyObject().(*appsv1.StatefulSet)}
+	assert.NoError(t, r.handleUpdate(baseNexus, requiredRes, deployedRes))
 }
 
 func newFakeReconcileNexus(cl *test.FakeClient) ReconcileNexus {
@@ -0,0 +1,68 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nexus
+
+import (
+	"context"
+
+	appsv1alpha1 "github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/deployment"
+	"github.com/m88i/nexus-operator/pkg/framework"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// referencingNexusMapper enqueues the Nexus instances whose pods reference the changed ConfigMap or Secret,
+// so their checksum can be updated and the pods rolled out
+func referencingNexusMapper(c client.Client) handler.ToRequestsFunc {
+	return func(object handler.MapObject) []reconcile.Request {
+		nexusList := &appsv1alpha1.NexusList{}
+		if err := c.List(context.TODO(), nexusList, client.InNamespace(object.Meta.GetNamespace())); err != nil {
+			log.Errorf("Unable to list the Nexus instances referencing %s: %v", object.Meta.GetName(), err)
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range nexusList.Items {
+			nexus := &nexusList.Items[i]
+			template, err := deployedPodTemplate(c, nexus)
+			if err != nil {
+				continue
+			}
+			if deployment.IsReferenced(&template.Spec, object.Object) {
+				requests = append(requests, reconcile.Request{NamespacedName: framework.Key(nexus)})
+			}
+		}
+		return requests
+	}
+}
+
+// deployedPodTemplate fetches the pod template from the workload running the Nexus pods
+func deployedPodTemplate(c client.Client, nexus *appsv1alpha1.Nexus) (*corev1.PodTemplateSpec, error) {
+	if nexus.Spec.WorkloadType == appsv1alpha1.StatefulSetWorkloadType {
+		deployed := &appsv1.StatefulSet{}
+		if err := framework.Fetch(c, framework.Key(nexus), deployed); err != nil {
+			return nil, err
+		}
+		return &deployed.Spec.Template, nil
+	}
+	deployed := &appsv1.Deployment{}
+	if err := framework.Fetch(c, framework.Key(nexus), deployed); err != nil {
+		return nil, err
+	}
+	return &deployed.Spec.Template, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nexus
+
+import (
+	"testing"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_referencingNexusMapper(t *testing.T) {
+	ns := t.Name()
+	referencing := &v1alpha1.Nexus{ObjectMeta: metav1.ObjectMeta{Name: "referencing", Namespace: ns}}
+	other := &v1alpha1.Nexus{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: ns}}
+	referencingDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: referencing.Name, Namespace: ns},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:    "nexus-server",
+				EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"}}}},
+			}},
+		}}},
+	}
+	otherDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: other.Name, Namespace: ns},
+		Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "nexus-server"}}}}},
+	}
+	cli := test.NewFakeClientBuilder(referencing, other, referencingDeployment, otherDeployment).Build()
+	mapper := referencingNexusMapper(cli)
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: ns}}
+	requests := mapper(handler.MapObject{Meta: secret, Object: secret})
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: ns, Name: referencing.Name}}}, requests)
+
+	// same name, different kind
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: ns}}
+	assert.Empty(t, mapper(handler.MapObject{Meta: configMap, Object: configMap}))
+
+	// same name, different namespace
+	secret.Namespace = "another-namespace"
+	assert.Empty(t, mapper(handler.MapObject{Meta: secret, Object: secret}))
+}
+
+func Test_referencingNexusMapper_statefulSet(t *testing.T) {
+	ns := t.Name()
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus", Namespace: ns},
+		Spec:       v1alpha1.NexusSpec{WorkloadType: v1alpha1.StatefulSetWorkloadType},
+	}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: nexus.Name, Namespace: ns},
+		Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:    "nexus-server",
+				EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"}}}},
+			}},
+		}}},
+	}
+	mapper := referencingNexusMapper(test.NewFakeClientBuilder(nexus, statefulSet).Build())
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: ns}}
+	requests := mapper(handler.MapObject{Meta: secret, Object: secret})
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: ns, Name: nexus.Name}}}, requests)
+}
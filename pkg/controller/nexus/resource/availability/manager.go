@@ -0,0 +1,97 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package availability
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/RHsyseng/operator-utils/pkg/resource"
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/framework"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Manager is responsible for creating the resources keeping the Nexus pods available, fetching deployed ones and comparing them
+// Use with zero values will result in a panic. Use the NewManager function to get a properly initialized manager
+type Manager struct {
+	nexus  *v1alpha1.Nexus
+	client client.Client
+}
+
+// NewManager creates an availability resources manager
+// It is expected that the Nexus has been previously validated.
+func NewManager(nexus *v1alpha1.Nexus, client client.Client) *Manager {
+	return &Manager{
+		nexus:  nexus,
+		client: client,
+	}
+}
+
+// GetRequiredResources returns the resources initialized by the manager
+func (m *Manager) GetRequiredResources() ([]resource.KubernetesResource, error) {
+	var resources []resource.KubernetesResource
+	// a budget protecting a single pod would block node drains, so it's only created with several replicas
+	if !m.nexus.Spec.DisruptionBudget.Disabled && m.nexus.Spec.Replicas > 1 {
+		resources = append(resources, newPodDisruptionBudget(m.nexus))
+	}
+	return resources, nil
+}
+
+// GetDeployedResources returns the availability resources deployed on the cluster
+func (m *Manager) GetDeployedResources() ([]resource.KubernetesResource, error) {
+	var resources []resource.KubernetesResource
+	budget := &policyv1beta1.PodDisruptionBudget{}
+	if err := framework.Fetch(m.client, framework.Key(m.nexus), budget); err == nil {
+		resources = append(resources, budget)
+	} else if !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("could not fetch Resource Pod Disruption Budget (%s): %v", m.nexus.Name, err)
+	}
+	return resources, nil
+}
+
+// GetCustomComparator returns the custom comp function used to compare an availability resource.
+// Returns nil if there is none
+func (m *Manager) GetCustomComparator(t reflect.Type) func(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
+	if t == reflect.TypeOf(&policyv1beta1.PodDisruptionBudget{}) {
+		return podDisruptionBudgetEqual
+	}
+	return nil
+}
+
+// GetCustomComparators returns all custom comp functions in a map indexed by the resource type
+// Returns nil if there are none
+func (m *Manager) GetCustomComparators() map[reflect.Type]func(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
+	return map[reflect.Type]func(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool{
+		reflect.TypeOf(policyv1beta1.PodDisruptionBudget{}): podDisruptionBudgetEqual,
+	}
+}
+
+func podDisruptionBudgetEqual(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
+	depBudget := deployed.(*policyv1beta1.PodDisruptionBudget)
+	reqBudget := requested.(*policyv1beta1.PodDisruptionBudget)
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{depBudget.Name, reqBudget.Name})
+	pairs = append(pairs, [2]interface{}{depBudget.Namespace, reqBudget.Namespace})
+	pairs = append(pairs, [2]interface{}{depBudget.Labels, reqBudget.Labels})
+	pairs = append(pairs, [2]interface{}{depBudget.Annotations, reqBudget.Annotations})
+	pairs = append(pairs, [2]interface{}{depBudget.Spec.Selector, reqBudget.Spec.Selector})
+	pairs = append(pairs, [2]interface{}{depBudget.Spec.MinAvailable, reqBudget.Spec.MinAvailable})
+	pairs = append(pairs, [2]interface{}{depBudget.Spec.MaxUnavailable, reqBudget.Spec.MaxUnavailable})
+	return compare.EqualPairs(pairs)
+}
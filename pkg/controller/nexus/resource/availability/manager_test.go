@@ -0,0 +1,165 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package availability
+
+import (
+	ctx "context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var minAvailable = intstr.FromInt(1)
+
+var baseNexus = &v1alpha1.Nexus{
+	ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "nexus"},
+	Spec:       v1alpha1.NexusSpec{Replicas: 2, DisruptionBudget: v1alpha1.NexusDisruptionBudget{MinAvailable: &minAvailable}},
+}
+
+func TestNewManager(t *testing.T) {
+	// default-setting logic is tested elsewhere
+	// so here we just check if the resulting manager took in the arguments correctly
+	nexus := baseNexus
+	client := test.NewFakeClientBuilder().Build()
+	want := &Manager{
+		nexus:  nexus,
+		client: client,
+	}
+	got := NewManager(nexus, client)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("TestNewManager()\nWant: %+v\tGot: %+v", want, got)
+	}
+}
+
+func TestManager_GetRequiredResources(t *testing.T) {
+	mgr := &Manager{
+		nexus:  baseNexus.DeepCopy(),
+		client: test.NewFakeClientBuilder().Build(),
+	}
+	resources, err := mgr.GetRequiredResources()
+	assert.Nil(t, err)
+	assert.Len(t, resources, 1)
+	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&policyv1beta1.PodDisruptionBudget{})))
+
+	// no budget with a single replica
+	mgr.nexus.Spec.Replicas = 1
+	resources, err = mgr.GetRequiredResources()
+	assert.Nil(t, err)
+	assert.Len(t, resources, 0)
+
+	// no budget when disabled
+	mgr.nexus.Spec.Replicas = 2
+	mgr.nexus.Spec.DisruptionBudget.Disabled = true
+	resources, err = mgr.GetRequiredResources()
+	assert.Nil(t, err)
+	assert.Len(t, resources, 0)
+}
+
+func TestManager_GetDeployedResources(t *testing.T) {
+	// first with no deployed resources
+	fakeClient := test.NewFakeClientBuilder().Build()
+	mgr := &Manager{
+		nexus:  baseNexus,
+		client: fakeClient,
+	}
+	resources, err := mgr.GetDeployedResources()
+	assert.Nil(t, resources)
+	assert.NoError(t, err)
+
+	// now with a deployed budget
+	budget := &policyv1beta1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: mgr.nexus.Name, Namespace: mgr.nexus.Namespace}}
+	assert.NoError(t, mgr.client.Create(ctx.TODO(), budget))
+	resources, err = mgr.GetDeployedResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 1)
+	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&policyv1beta1.PodDisruptionBudget{})))
+
+	// make the client return a mocked 500 response to test errors other than NotFound
+	mockErrorMsg := "mock 500"
+	fakeClient.SetMockErrorForOneRequest(errors.NewInternalError(fmt.Errorf(mockErrorMsg)))
+	resources, err = mgr.GetDeployedResources()
+	assert.Nil(t, resources)
+	assert.Contains(t, err.Error(), mockErrorMsg)
+}
+
+func TestManager_GetCustomComparator(t *testing.T) {
+	// the nexus and the client should have no effect on the
+	// comparator functions offered by the manager
+	mgr := &Manager{}
+	assert.NotNil(t, mgr.GetCustomComparator(reflect.TypeOf(&policyv1beta1.PodDisruptionBudget{})))
+	assert.Nil(t, mgr.GetCustomComparator(reflect.TypeOf(&corev1.Service{})))
+}
+
+func TestManager_GetCustomComparators(t *testing.T) {
+	// the nexus and the client should have no effect on the
+	// comparator functions offered by the manager
+	mgr := &Manager{}
+	comparators := mgr.GetCustomComparators()
+	assert.Len(t, comparators, 1)
+	assert.NotNil(t, comparators[reflect.TypeOf(policyv1beta1.PodDisruptionBudget{})])
+}
+
+func Test_podDisruptionBudgetEqual(t *testing.T) {
+	base := newPodDisruptionBudget(baseNexus)
+	maxUnavailable := intstr.FromString("50%")
+	tests := []struct {
+		name      string
+		req       *policyv1beta1.PodDisruptionBudget
+		dep       *policyv1beta1.PodDisruptionBudget
+		wantEqual bool
+	}{
+		{
+			"Equal budgets",
+			base.DeepCopy(),
+			base.DeepCopy(),
+			true,
+		},
+		{
+			"Different minAvailable",
+			func() *policyv1beta1.PodDisruptionBudget {
+				budget := base.DeepCopy()
+				budget.Spec.MinAvailable = &maxUnavailable
+				return budget
+			}(),
+			base.DeepCopy(),
+			false,
+		},
+		{
+			"minAvailable replaced by maxUnavailable",
+			func() *policyv1beta1.PodDisruptionBudget {
+				budget := base.DeepCopy()
+				budget.Spec.MinAvailable = nil
+				budget.Spec.MaxUnavailable = &maxUnavailable
+				return budget
+			}(),
+			base.DeepCopy(),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		if got := podDisruptionBudgetEqual(tt.dep, tt.req); got != tt.wantEqual {
+			t.Errorf("%s\nWant: %v\tGot: %v", tt.name, tt.wantEqual, got)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package availability
+
+import (
+	"testing"
+
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func Test_newPodDisruptionBudget(t *testing.T) {
+	nexus := baseNexus.DeepCopy()
+	budget := newPodDisruptionBudget(nexus)
+	assert.Equal(t, nexus.Name, budget.Name)
+	assert.Equal(t, nexus.Namespace, budget.Namespace)
+	assert.Equal(t, meta.GenerateLabels(nexus), budget.Spec.Selector.MatchLabels)
+	assert.Equal(t, intstr.FromInt(1), *budget.Spec.MinAvailable)
+	assert.Nil(t, budget.Spec.MaxUnavailable)
+
+	// the budget doesn't share the values from the Nexus spec
+	nexus.Spec.DisruptionBudget.MinAvailable.IntVal = 2
+	assert.Equal(t, int32(1), budget.Spec.MinAvailable.IntVal)
+
+	maxUnavailable := intstr.FromString("50%")
+	nexus.Spec.DisruptionBudget.MinAvailable = nil
+	nexus.Spec.DisruptionBudget.MaxUnavailable = &maxUnavailable
+	budget = newPodDisruptionBudget(nexus)
+	assert.Nil(t, budget.Spec.MinAvailable)
+	assert.Equal(t, maxUnavailable, *budget.Spec.MaxUnavailable)
+}
@@ -0,0 +1,144 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/m88i/nexus-operator/pkg/framework"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ConfigChecksumAnnotation holds the checksum of every ConfigMap and Secret referenced by the Nexus pod, so changing them triggers a rollout
+const ConfigChecksumAnnotation = "apps.m88i.io/config-checksum"
+
+// podReferences are the names of the ConfigMaps and Secrets referenced by a pod
+type podReferences struct {
+	configMaps sets.String
+	secrets    sets.String
+}
+
+func newPodReferences(podSpec *corev1.PodSpec) *podReferences {
+	refs := &podReferences{configMaps: sets.NewString(), secrets: sets.NewString()}
+	for _, volume := range podSpec.Volumes {
+		if volume.ConfigMap != nil {
+			refs.configMaps.Insert(volume.ConfigMap.Name)
+		}
+		if volume.Secret != nil {
+			refs.secrets.Insert(volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					refs.configMaps.Insert(source.ConfigMap.Name)
+				}
+				if source.Secret != nil {
+					refs.secrets.Insert(source.Secret.Name)
+				}
+			}
+		}
+	}
+	containers := append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				refs.configMaps.Insert(envFrom.ConfigMapRef.Name)
+			}
+			if envFrom.SecretRef != nil {
+				refs.secrets.Insert(envFrom.SecretRef.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				refs.configMaps.Insert(env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				refs.secrets.Insert(env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+	return refs
+}
+
+// IsReferenced verifies if the given ConfigMap or Secret is referenced by the pod
+func IsReferenced(podSpec *corev1.PodSpec, object runtime.Object) bool {
+	refs := newPodReferences(podSpec)
+	switch obj := object.(type) {
+	case *corev1.ConfigMap:
+		return refs.configMaps.Has(obj.Name)
+	case *corev1.Secret:
+		return refs.secrets.Has(obj.Name)
+	}
+	return false
+}
+
+// addConfigChecksum annotates the pod template with the checksum of the contents of every ConfigMap and Secret it references.
+// Missing objects are ignored, since they might be optional.
+func (m *Manager) addConfigChecksum(deployment *appsv1.Deployment) error {
+	refs := newPodReferences(&deployment.Spec.Template.Spec)
+	// the properties ConfigMap has its own checksum, computed from the requested contents
+	refs.configMaps.Delete(propertiesConfigMapName(m.nexus))
+	// the instance Secret holds the operator user credentials, read by the preStop hook only when it runs
+	refs.secrets.Delete(m.nexus.Name)
+	if refs.configMaps.Len() == 0 && refs.secrets.Len() == 0 {
+		return nil
+	}
+
+	hash := sha256.New()
+	for _, name := range refs.configMaps.List() {
+		configMap := &corev1.ConfigMap{}
+		if err := framework.Fetch(m.client, types.NamespacedName{Namespace: m.nexus.Namespace, Name: name}, configMap); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("could not fetch ConfigMap %s referenced by the Nexus pod: %v", name, err)
+		}
+		fmt.Fprintf(hash, "configmap/%s\n", name)
+		for _, key := range sets.StringKeySet(configMap.Data).List() {
+			fmt.Fprintf(hash, "%s=%s\n", key, configMap.Data[key])
+		}
+		for _, key := range sets.StringKeySet(configMap.BinaryData).List() {
+			fmt.Fprintf(hash, "%s=%x\n", key, configMap.BinaryData[key])
+		}
+	}
+	for _, name := range refs.secrets.List() {
+		secret := &corev1.Secret{}
+		if err := framework.Fetch(m.client, types.NamespacedName{Namespace: m.nexus.Namespace, Name: name}, secret); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("could not fetch Secret %s referenced by the Nexus pod: %v", name, err)
+		}
+		fmt.Fprintf(hash, "secret/%s\n", name)
+		for _, key := range sets.StringKeySet(secret.Data).List() {
+			fmt.Fprintf(hash, "%s=%x\n", key, secret.Data[key])
+		}
+	}
+
+	if deployment.Spec.Template.Annotations == nil {
+		deployment.Spec.Template.Annotations = map[string]string{}
+	}
+	deployment.Spec.Template.Annotations[ConfigChecksumAnnotation] = fmt.Sprintf("%x", hash.Sum(nil))
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	ctx "context"
+	"fmt"
+	"testing"
+
+	"github.com/m88i/nexus-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsReferenced(t *testing.T) {
+	podSpec := &corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "ca", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}}}},
+			{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "tls"}}},
+			{Name: "projected", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+				{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "projected-config"}}},
+			}}}},
+		},
+		InitContainers: []corev1.Container{{
+			Name: "init",
+			Env:  []corev1.EnvVar{{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "token"}, Key: "token"}}}},
+		}},
+		Containers: []corev1.Container{{
+			Name:    nexusContainerName,
+			EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}}}},
+		}},
+	}
+
+	for _, name := range []string{"ca", "projected-config", "env"} {
+		assert.True(t, IsReferenced(podSpec, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name}}), name)
+		assert.False(t, IsReferenced(podSpec, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name}}), name)
+	}
+	for _, name := range []string{"tls", "token"} {
+		assert.True(t, IsReferenced(podSpec, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name}}), name)
+	}
+	assert.False(t, IsReferenced(podSpec, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unknown"}}))
+}
+
+func TestManager_addConfigChecksum(t *testing.T) {
+	nexus := allDefaultsCommunityNexus.DeepCopy()
+	nexus.Spec.EnvFrom = []corev1.EnvFromSource{
+		{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}}},
+		// optional and missing
+		{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}}},
+	}
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "env", Namespace: nexus.Namespace}, Data: map[string]string{"FOO": "bar"}}
+	cli := test.NewFakeClientBuilder(configMap).Build()
+	mgr := NewManager(nexus, cli)
+
+	deployment := newDeployment(nexus)
+	assert.NoError(t, mgr.addConfigChecksum(deployment))
+	checksum := deployment.Spec.Template.Annotations[ConfigChecksumAnnotation]
+	assert.NotEmpty(t, checksum)
+
+	// unchanged contents, same checksum
+	deployment = newDeployment(nexus)
+	assert.NoError(t, mgr.addConfigChecksum(deployment))
+	assert.Equal(t, checksum, deployment.Spec.Template.Annotations[ConfigChecksumAnnotation])
+
+	configMap.Data["FOO"] = "baz"
+	assert.NoError(t, cli.Update(ctx.TODO(), configMap))
+	deployment = newDeployment(nexus)
+	assert.NoError(t, mgr.addConfigChecksum(deployment))
+	assert.NotEqual(t, checksum, deployment.Spec.Template.Annotations[ConfigChecksumAnnotation])
+
+	cli.SetMockErrorForOneRequest(errors.NewInternalError(fmt.Errorf("mock 500")))
+	assert.Error(t, mgr.addConfigChecksum(newDeployment(nexus)))
+}
+
+func TestManager_addConfigChecksum_noReferences(t *testing.T) {
+	nexus := allDefaultsCommunityNexus.DeepCopy()
+	// has its own checksum
+	nexus.Spec.Properties = map[string]string{"nexus.scripts.allowCreation": "true"}
+	// the instance Secret only holds the operator user credentials, which must not trigger a rollout when created
+	nexus.Spec.FreezeOnShutdown = true
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: nexus.Name, Namespace: nexus.Namespace}, Data: map[string][]byte{"server-user-username": []byte("nexus-operator")}}
+	mgr := NewManager(nexus, test.NewFakeClientBuilder(newPropertiesConfigMap(nexus), secret).Build())
+
+	deployment := newDeployment(nexus)
+	assert.NoError(t, mgr.addConfigChecksum(deployment))
+	assert.NotContains(t, deployment.Spec.Template.Annotations, ConfigChecksumAnnotation)
+}
@@ -21,6 +21,8 @@ import (
 	"strings"
 
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/validation"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/update"
 
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -42,6 +44,7 @@ const (
 	jvmArgsMaxMemSize          = "-XX:MaxDirectMemorySize"
 	jvmArgsUserRoot            = "-Djava.util.prefs.userRoot"
 	jvmArgRandomPassword       = "-Dnexus.security.randompassword"
+	jvmArgsUserRootDefault     = "${NEXUS_DATA}/javaprefs"
 	heapSizeDefault            = "1718m"
 	maxDirectMemorySizeDefault = "2148m"
 	nexusDataDir               = "/nexus-data"
@@ -49,29 +52,28 @@ const (
 )
 
 var (
-	jvmArgsMap = map[string]string{
-		jvmArgsXms:           heapSizeDefault,
-		jvmArgsXmx:           heapSizeDefault,
-		jvmArgsMaxMemSize:    maxDirectMemorySizeDefault,
-		jvmArgsUserRoot:      "${NEXUS_DATA}/javaprefs",
-		jvmArgRandomPassword: "false",
-	}
-
 	nexusUID = int64(200)
+
+	jvmGarbageCollectorFlags = map[v1alpha1.NexusJVMGarbageCollector]string{
+		v1alpha1.G1GarbageCollector:       "-XX:+UseG1GC",
+		v1alpha1.ParallelGarbageCollector: "-XX:+UseParallelGC",
+		v1alpha1.SerialGarbageCollector:   "-XX:+UseSerialGC",
+	}
 )
 
 func newDeployment(nexus *v1alpha1.Nexus) *appsv1.Deployment {
 	deployment := &appsv1.Deployment{
 		ObjectMeta: meta.DefaultObjectMeta(nexus),
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &nexus.Spec.Replicas,
+			Replicas: Replicas(nexus),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: meta.GenerateLabels(nexus),
 			},
 			Template: corev1.PodTemplateSpec{
-				ObjectMeta: meta.DefaultObjectMeta(nexus),
+				ObjectMeta: meta.DefaultPodObjectMeta(nexus),
 				Spec: corev1.PodSpec{
 					ServiceAccountName: nexus.Spec.ServiceAccountName,
+					ImagePullSecrets:   nexus.Spec.ImagePullSecrets,
 					Containers: []corev1.Container{
 						{
 							Name: nexusContainerName,
@@ -83,7 +85,7 @@ func newDeployment(nexus *v1alpha1.Nexus) *appsv1.Deployment {
 								},
 							},
 							Resources: nexus.Spec.Resources,
-							Image:     nexus.Spec.Image,
+							Image:     update.MirroredImage(nexus.Spec.Image),
 						},
 					},
 				},
@@ -91,15 +93,45 @@ func newDeployment(nexus *v1alpha1.Nexus) *appsv1.Deployment {
 		},
 	}
 
+	addExtraPorts(nexus, deployment)
 	addVolume(nexus, deployment)
+	addProperties(nexus, deployment)
+	addTrustedCAs(nexus, deployment)
+	addCustomVolumes(nexus, deployment)
 	addProbes(nexus, deployment)
 	applyJVMArgs(nexus, deployment)
+	addCustomEnv(nexus, deployment)
+	addCustomContainers(nexus, deployment)
+	applyScheduling(nexus, deployment)
+	applyStrategy(nexus, deployment)
+	applyShutdown(nexus, deployment)
 	applySecurityContext(nexus, deployment)
 	applyPullPolicy(nexus, deployment)
 
 	return deployment
 }
 
+// addExtraPorts adds the ports informed in 'spec.extraPorts' to the Nexus container.
+// The Docker connectors found in the server are left out, restarting the server whenever a repository is created isn't worth it.
+func addExtraPorts(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
+	for _, port := range nexus.Spec.ExtraPorts {
+		deployment.Spec.Template.Spec.Containers[0].Ports = append(deployment.Spec.Template.Spec.Containers[0].Ports, corev1.ContainerPort{
+			Name:          port.Name,
+			ContainerPort: port.Port,
+			Protocol:      port.Protocol,
+		})
+	}
+}
+
+// Replicas returns how many Nexus pods should be running: none once the server has been frozen for a maintenance scaling the instance down
+func Replicas(nexus *v1alpha1.Nexus) *int32 {
+	replicas := nexus.Spec.Replicas
+	if nexus.Spec.Maintenance && nexus.Spec.ScaleDownOnMaintenance && nexus.Status.MaintenanceFrozen {
+		replicas = 0
+	}
+	return &replicas
+}
+
 func applyPullPolicy(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
 	if len(nexus.Spec.ImagePullPolicy) > 0 {
 		deployment.Spec.Template.Spec.Containers[0].ImagePullPolicy = nexus.Spec.ImagePullPolicy
@@ -107,24 +139,15 @@ func applyPullPolicy(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
 }
 
 func addProbes(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
-	livenessProbe := &corev1.Probe{
-		Handler: corev1.Handler{
-			HTTPGet: &corev1.HTTPGetAction{
-				Path: "/service/rest/v1/status",
-				Port: intstr.IntOrString{
-					IntVal: NexusServicePort,
-				},
-				Scheme: corev1.URISchemeHTTP,
-			},
-		},
-		InitialDelaySeconds: nexus.Spec.LivenessProbe.InitialDelaySeconds,
-		TimeoutSeconds:      nexus.Spec.LivenessProbe.TimeoutSeconds,
-		FailureThreshold:    nexus.Spec.LivenessProbe.FailureThreshold,
-		PeriodSeconds:       nexus.Spec.LivenessProbe.PeriodSeconds,
-		SuccessThreshold:    nexus.Spec.LivenessProbe.SuccessThreshold,
+	deployment.Spec.Template.Spec.Containers[0].LivenessProbe = newProbe(nexus.Spec.LivenessProbe)
+	deployment.Spec.Template.Spec.Containers[0].ReadinessProbe = newProbe(nexus.Spec.ReadinessProbe)
+	if nexus.Spec.StartupProbe != nil {
+		deployment.Spec.Template.Spec.Containers[0].StartupProbe = newProbe(nexus.Spec.StartupProbe)
 	}
+}
 
-	readinessProbe := &corev1.Probe{
+func newProbe(probe *v1alpha1.NexusProbe) *corev1.Probe {
+	return &corev1.Probe{
 		Handler: corev1.Handler{
 			HTTPGet: &corev1.HTTPGetAction{
 				Path: "/service/rest/v1/status",
@@ -134,22 +157,24 @@ func addProbes(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
 				Scheme: corev1.URISchemeHTTP,
 			},
 		},
-		InitialDelaySeconds: nexus.Spec.ReadinessProbe.InitialDelaySeconds,
-		TimeoutSeconds:      nexus.Spec.ReadinessProbe.TimeoutSeconds,
-		FailureThreshold:    nexus.Spec.ReadinessProbe.FailureThreshold,
-		PeriodSeconds:       nexus.Spec.ReadinessProbe.PeriodSeconds,
-		SuccessThreshold:    nexus.Spec.ReadinessProbe.SuccessThreshold,
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		TimeoutSeconds:      probe.TimeoutSeconds,
+		FailureThreshold:    probe.FailureThreshold,
+		PeriodSeconds:       probe.PeriodSeconds,
+		SuccessThreshold:    probe.SuccessThreshold,
 	}
+}
 
-	deployment.Spec.Template.Spec.Containers[0].LivenessProbe = livenessProbe
-	deployment.Spec.Template.Spec.Containers[0].ReadinessProbe = readinessProbe
+// DataVolumeName is the name of the pod volume holding the server data directory
+func DataVolumeName(nexus *v1alpha1.Nexus) string {
+	return fmt.Sprintf("%s-data", nexus.Name)
 }
 
 func addVolume(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
 	if nexus.Spec.Persistence.Persistent {
 		deployment.Spec.Template.Spec.Volumes = []corev1.Volume{
 			{
-				Name: fmt.Sprintf("%s-data", nexus.Name),
+				Name: DataVolumeName(nexus),
 				VolumeSource: corev1.VolumeSource{
 					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
 						ClaimName: nexus.Name,
@@ -160,52 +185,158 @@ func addVolume(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
 		}
 		deployment.Spec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{
 			{
-				Name:      fmt.Sprintf("%s-data", nexus.Name),
+				Name:      DataVolumeName(nexus),
 				MountPath: nexusDataDir,
 			},
 		}
 	}
 }
 
-func applyJVMArgs(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
-	jvmMemory, directMemSize := calculateJVMMemory(deployment.Spec.Template.Spec.Containers[0].Resources.Limits)
-	jvmArgsMap[jvmArgsXms] = jvmMemory
-	jvmArgsMap[jvmArgsXmx] = jvmMemory
-	jvmArgsMap[jvmArgsMaxMemSize] = directMemSize
-	jvmArgsMap[jvmArgRandomPassword] = strconv.FormatBool(nexus.Spec.GenerateRandomAdminPassword)
+// addCustomVolumes appends the volumes and mounts informed by the user after the ones managed by the operator
+func addCustomVolumes(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
+	if len(nexus.Spec.Volumes) > 0 {
+		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, nexus.Spec.Volumes...)
+	}
+	if len(nexus.Spec.VolumeMounts) > 0 {
+		deployment.Spec.Template.Spec.Containers[0].VolumeMounts =
+			append(deployment.Spec.Template.Spec.Containers[0].VolumeMounts, nexus.Spec.VolumeMounts...)
+	}
+}
 
-	// we cannot guarantee the key order when transforming the map into a single string.
-	// this might create different strings across the reconciliation loop, causing the comparator to accuse the deployment to be different
-	// that's why we have to sort the map every time.
-	var jvmArgs strings.Builder
-	var jvmArgsKeys []string
-	for key := range jvmArgsMap {
-		jvmArgsKeys = append(jvmArgsKeys, key)
-	}
-	sort.Strings(jvmArgsKeys)
-	for _, key := range jvmArgsKeys {
-		jvmArgs.WriteString(key)
-		if key != jvmArgsXms && key != jvmArgsXmx {
-			jvmArgs.WriteString("=")
+// addCustomEnv appends the environment variables informed by the user after the ones managed by the operator
+func addCustomEnv(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
+	for _, env := range nexus.Spec.Env {
+		// the JVM args must be set via 'spec.jvm'
+		if env.Name == JvmArgsEnvKey {
+			continue
 		}
-		jvmArgs.WriteString(jvmArgsMap[key])
-		jvmArgs.WriteString(" ")
+		deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env, env)
+	}
+	if len(nexus.Spec.EnvFrom) > 0 {
+		deployment.Spec.Template.Spec.Containers[0].EnvFrom = nexus.Spec.EnvFrom
+	}
+}
+
+// addCustomContainers appends the sidecars and init containers informed by the user.
+// The Nexus container must always be the first one in the pod.
+func addCustomContainers(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
+	if len(nexus.Spec.Sidecars) > 0 {
+		deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers, nexus.Spec.Sidecars...)
+	}
+	if len(nexus.Spec.InitContainers) > 0 {
+		deployment.Spec.Template.Spec.InitContainers = append(deployment.Spec.Template.Spec.InitContainers, nexus.Spec.InitContainers...)
+	}
+}
+
+func applyStrategy(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
+	if len(nexus.Spec.DeploymentStrategy.Type) > 0 {
+		deployment.Spec.Strategy = nexus.Spec.DeploymentStrategy
+	} else if nexus.Spec.Persistence.Persistent && nexus.Spec.Replicas <= 1 {
+		// the volume is ReadWriteOnce, a rolling update would never finish
+		deployment.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	}
+}
+
+func applyScheduling(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
+	podSpec := &deployment.Spec.Template.Spec
+	if len(nexus.Spec.NodeSelector) > 0 {
+		podSpec.NodeSelector = nexus.Spec.NodeSelector
+	}
+	if len(nexus.Spec.Tolerations) > 0 {
+		podSpec.Tolerations = nexus.Spec.Tolerations
+	}
+	if len(nexus.Spec.TopologySpreadConstraints) > 0 {
+		podSpec.TopologySpreadConstraints = nexus.Spec.TopologySpreadConstraints
 	}
+	podSpec.Affinity = nexus.Spec.Affinity
+	podSpec.PriorityClassName = nexus.Spec.PriorityClassName
+}
 
+func applyJVMArgs(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
 	deployment.Spec.Template.Spec.Containers[0].Env =
 		append(deployment.Spec.Template.Spec.Containers[0].Env,
 			corev1.EnvVar{
 				Name:  JvmArgsEnvKey,
-				Value: jvmArgs.String(),
+				Value: buildJVMArgs(nexus, deployment.Spec.Template.Spec.Containers[0].Resources.Limits),
 			})
 }
 
-func calculateJVMMemory(limits corev1.ResourceList) (jvmMemory, directMemSize string) {
+// buildJVMArgs creates the JVM arguments for the given Nexus instance. A new set of arguments is built on every call,
+// so there's no state shared among instances.
+func buildJVMArgs(nexus *v1alpha1.Nexus, limits corev1.ResourceList) string {
+	jvmMemory, directMemSize := calculateJVMMemory(limits, nexus.Spec.JVM.HeapPercentage)
+	if len(nexus.Spec.JVM.MaxDirectMemorySize) > 0 {
+		directMemSize = nexus.Spec.JVM.MaxDirectMemorySize
+	}
+	args := []string{
+		jvmArgsXms + jvmMemory,
+		jvmArgsXmx + jvmMemory,
+		jvmArgsMaxMemSize + "=" + directMemSize,
+		jvmArgsUserRoot + "=" + jvmArgsUserRootDefault,
+		jvmArgRandomPassword + "=" + strconv.FormatBool(nexus.Spec.GenerateRandomAdminPassword),
+	}
+	// the JVM refuses to start with more than one collector, so the one selected in the extra args wins
+	if gcFlag, ok := jvmGarbageCollectorFlags[nexus.Spec.JVM.GarbageCollector]; ok && !selectsGarbageCollector(nexus.Spec.JVM.ExtraArgs) {
+		args = append(args, gcFlag)
+	}
+	for key, value := range trustStoreJVMArgs(nexus) {
+		args = append(args, key+"="+value)
+	}
+	// user informed args take precedence over the ones we set
+	args = append(args, nexus.Spec.JVM.ExtraArgs...)
+	jvmArgsMap := make(map[string]string, len(args))
+	for _, arg := range args {
+		jvmArgsMap[jvmArgKey(arg)] = arg
+	}
+
+	// we cannot guarantee the key order when transforming the map into a single string.
+	// this might create different strings across the reconciliation loop, causing the comparator to accuse the deployment to be different
+	// that's why we have to sort the args every time.
+	var jvmArgs strings.Builder
+	var sortedArgs []string
+	for _, arg := range jvmArgsMap {
+		sortedArgs = append(sortedArgs, arg)
+	}
+	sort.Strings(sortedArgs)
+	for _, arg := range sortedArgs {
+		jvmArgs.WriteString(arg)
+		jvmArgs.WriteString(" ")
+	}
+	return jvmArgs.String()
+}
+
+// selectsGarbageCollector checks if any of the arguments enables a garbage collector, such as "-XX:+UseZGC"
+func selectsGarbageCollector(args []string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-XX:+Use") && strings.HasSuffix(arg, "GC") {
+			return true
+		}
+	}
+	return false
+}
+
+// jvmArgKey returns what identifies an argument, so the user can replace the ones we set: the name of a property
+// such as "-Dkey=value" ("-Dkey") or the name of a boolean flag regardless of its sign ("-XX:UseG1GC" for both
+// "-XX:+UseG1GC" and "-XX:-UseG1GC")
+func jvmArgKey(arg string) string {
+	if i := strings.Index(arg, "="); i >= 0 {
+		arg = arg[:i]
+	}
+	if strings.HasPrefix(arg, "-XX:+") || strings.HasPrefix(arg, "-XX:-") {
+		return "-XX:" + arg[len("-XX:+"):]
+	}
+	return arg
+}
+
+func calculateJVMMemory(limits corev1.ResourceList, heapPercentage int32) (jvmMemory, directMemSize string) {
+	if heapPercentage <= 0 || heapPercentage > 100 {
+		heapPercentage = validation.DefaultHeapPercentage
+	}
 	if limits != nil {
 		memoryLimit := limits.Memory()
 		if memoryLimit != nil {
 			limitValue := memoryLimit.ScaledValue(resource.Mega)
-			jvmMemory = fmt.Sprintf("%.0fm", float64(limitValue)*0.8)
+			jvmMemory = fmt.Sprintf("%.0fm", float64(limitValue)*float64(heapPercentage)/100)
 			directMemSize = fmt.Sprintf("%dm", limitValue)
 			return
 		}
@@ -215,10 +346,63 @@ func calculateJVMMemory(limits corev1.ResourceList) (jvmMemory, directMemSize st
 	return
 }
 
+// applySecurityContext merges the security context informed by the user with the defaults. The container security context
+// is also applied to the init containers created by the Operator, since they run the server image as well.
 func applySecurityContext(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
 	var podSecContext *corev1.PodSecurityContext
 	if !nexus.Spec.UseRedHatImage {
 		podSecContext = &corev1.PodSecurityContext{FSGroup: &nexusUID, RunAsUser: &nexusUID, SupplementalGroups: []int64{nexusUID}}
 	}
+	if nexus.Spec.SecurityContext != nil {
+		podSecContext = mergePodSecurityContext(podSecContext, nexus.Spec.SecurityContext)
+	}
 	deployment.Spec.Template.Spec.SecurityContext = podSecContext
+
+	if nexus.Spec.ContainerSecurityContext == nil {
+		return
+	}
+	podSpec := &deployment.Spec.Template.Spec
+	podSpec.Containers[0].SecurityContext = nexus.Spec.ContainerSecurityContext.DeepCopy()
+	for i := range podSpec.InitContainers {
+		if podSpec.InitContainers[i].Name == propertiesInitContainerName || podSpec.InitContainers[i].Name == trustedCAsInitContainerName {
+			podSpec.InitContainers[i].SecurityContext = nexus.Spec.ContainerSecurityContext.DeepCopy()
+		}
+	}
+}
+
+// mergePodSecurityContext returns a copy of the defaults with every field set in the overrides replaced
+func mergePodSecurityContext(defaults, overrides *corev1.PodSecurityContext) *corev1.PodSecurityContext {
+	merged := &corev1.PodSecurityContext{}
+	if defaults != nil {
+		merged = defaults.DeepCopy()
+	}
+	overrides = overrides.DeepCopy()
+	if overrides.SELinuxOptions != nil {
+		merged.SELinuxOptions = overrides.SELinuxOptions
+	}
+	if overrides.WindowsOptions != nil {
+		merged.WindowsOptions = overrides.WindowsOptions
+	}
+	if overrides.RunAsUser != nil {
+		merged.RunAsUser = overrides.RunAsUser
+	}
+	if overrides.RunAsGroup != nil {
+		merged.RunAsGroup = overrides.RunAsGroup
+	}
+	if overrides.RunAsNonRoot != nil {
+		merged.RunAsNonRoot = overrides.RunAsNonRoot
+	}
+	if overrides.SupplementalGroups != nil {
+		merged.SupplementalGroups = overrides.SupplementalGroups
+	}
+	if overrides.FSGroup != nil {
+		merged.FSGroup = overrides.FSGroup
+	}
+	if overrides.Sysctls != nil {
+		merged.Sysctls = overrides.Sysctls
+	}
+	if overrides.FSGroupChangePolicy != nil {
+		merged.FSGroupChangePolicy = overrides.FSGroupChangePolicy
+	}
+	return merged
 }
@@ -15,17 +15,21 @@
 package deployment
 
 import (
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/validation"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/update"
 
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func Test_newDeployment_WithoutPersistence(t *testing.T) {
@@ -95,7 +99,8 @@ func Test_newDeployment_WithPersistence(t *testing.T) {
 // see: https://stackoverflow.com/questions/50804915/kubernetes-size-definitions-whats-the-difference-of-gi-and-g
 func Test_calculateJVMMemory(t *testing.T) {
 	type args struct {
-		limits corev1.ResourceList
+		limits         corev1.ResourceList
+		heapPercentage int32
 	}
 	tests := []struct {
 		name              string
@@ -127,10 +132,22 @@ func Test_calculateJVMMemory(t *testing.T) {
 			"8000m",
 			"10000m",
 		},
+		{
+			"4 Giga, half for the heap",
+			args{limits: map[corev1.ResourceName]resource.Quantity{corev1.ResourceMemory: resource.MustParse("4G")}, heapPercentage: 50},
+			"2000m",
+			"4000m",
+		},
+		{
+			"No limits",
+			args{heapPercentage: 50},
+			heapSizeDefault,
+			maxDirectMemorySizeDefault,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotJvmMemory, gotDirectMemSize := calculateJVMMemory(tt.args.limits)
+			gotJvmMemory, gotDirectMemSize := calculateJVMMemory(tt.args.limits, tt.args.heapPercentage)
 			if gotJvmMemory != tt.wantJvmMemory {
 				t.Errorf("calculateJVMMemory() gotJvmMemory = %v, want %v", gotJvmMemory, tt.wantJvmMemory)
 			}
@@ -162,6 +179,12 @@ func Test_customProbes(t *testing.T) {
 				TimeoutSeconds:      15,
 			},
 			ReadinessProbe: validation.DefaultProbe,
+			StartupProbe: &v1alpha1.NexusProbe{
+				FailureThreshold: 90,
+				PeriodSeconds:    10,
+				SuccessThreshold: 1,
+				TimeoutSeconds:   15,
+			},
 		},
 	}
 	deployment := newDeployment(nexus)
@@ -170,6 +193,8 @@ func Test_customProbes(t *testing.T) {
 	assert.Equal(t, int32(1), deployment.Spec.Template.Spec.Containers[0].LivenessProbe.FailureThreshold)
 	assert.Equal(t, int32(0), deployment.Spec.Template.Spec.Containers[0].LivenessProbe.InitialDelaySeconds)
 	assert.Equal(t, validation.DefaultProbe.InitialDelaySeconds, deployment.Spec.Template.Spec.Containers[0].ReadinessProbe.InitialDelaySeconds)
+	assert.Equal(t, int32(90), deployment.Spec.Template.Spec.Containers[0].StartupProbe.FailureThreshold)
+	assert.Equal(t, deployment.Spec.Template.Spec.Containers[0].LivenessProbe.Handler, deployment.Spec.Template.Spec.Containers[0].StartupProbe.Handler)
 }
 
 func Test_applyJVMArgs_withRandomPassword(t *testing.T) {
@@ -224,3 +249,308 @@ func Test_applyJVMArgs_withDefaultValues(t *testing.T) {
 	assert.Contains(t, deployment.Spec.Template.Spec.Containers[0].Env[0].Value, strings.Join([]string{jvmArgsXms, heapSizeDefault}, ""))
 	assert.Contains(t, deployment.Spec.Template.Spec.Containers[0].Env[0].Value, strings.Join([]string{jvmArgsXmx, heapSizeDefault}, ""))
 }
+
+func Test_buildJVMArgs(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec: v1alpha1.NexusSpec{
+			JVM: v1alpha1.NexusJVM{
+				HeapPercentage:      50,
+				MaxDirectMemorySize: "1024m",
+				GarbageCollector:    v1alpha1.G1GarbageCollector,
+				ExtraArgs:           []string{"-XX:+ExitOnOutOfMemoryError", "-Dnexus.licenseFile=/nexus-data/license.lic", "-Djava.util.prefs.userRoot=/tmp/prefs"},
+			},
+		},
+	}
+	limits := corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4G")}
+
+	args := buildJVMArgs(nexus, limits)
+	assert.Equal(t,
+		"-Djava.util.prefs.userRoot=/tmp/prefs -Dnexus.licenseFile=/nexus-data/license.lic -Dnexus.security.randompassword=false "+
+			"-XX:+ExitOnOutOfMemoryError -XX:+UseG1GC -XX:MaxDirectMemorySize=1024m -Xms2000m -Xmx2000m ",
+		args)
+	// must be deterministic
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, args, buildJVMArgs(nexus, limits))
+	}
+
+	// no state is shared between instances
+	other := &v1alpha1.Nexus{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: t.Name()}}
+	otherArgs := buildJVMArgs(other, nil)
+	assert.Contains(t, otherArgs, jvmArgsXms+heapSizeDefault)
+	assert.Contains(t, otherArgs, jvmArgsUserRoot+"="+jvmArgsUserRootDefault)
+	assert.NotContains(t, otherArgs, "UseG1GC")
+	assert.NotContains(t, otherArgs, "licenseFile")
+
+	// the collector selected in the extra args replaces the one from 'spec.jvm.garbageCollector'
+	nexus.Spec.JVM.ExtraArgs = []string{"-XX:+UseZGC"}
+	args = buildJVMArgs(nexus, limits)
+	assert.Contains(t, args, "-XX:+UseZGC")
+	assert.NotContains(t, args, "UseG1GC")
+
+	// a boolean flag is replaced by its negation
+	nexus.Spec.JVM.ExtraArgs = []string{"-XX:-UseG1GC", "-XX:+UseContainerSupport", "-XX:-UseContainerSupport"}
+	args = buildJVMArgs(nexus, limits)
+	assert.Contains(t, args, "-XX:-UseG1GC ")
+	assert.NotContains(t, args, "-XX:+UseG1GC")
+	assert.Contains(t, args, "-XX:-UseContainerSupport ")
+	assert.NotContains(t, args, "-XX:+UseContainerSupport")
+}
+
+func Test_selectsGarbageCollector(t *testing.T) {
+	assert.True(t, selectsGarbageCollector([]string{"-Dnexus.foo=bar", "-XX:+UseShenandoahGC"}))
+	assert.True(t, selectsGarbageCollector([]string{"-XX:+UseParallelGC"}))
+	assert.False(t, selectsGarbageCollector([]string{"-XX:+UseGCOverheadLimit", "-XX:-UseG1GC", "-XX:+ExitOnOutOfMemoryError"}))
+	assert.False(t, selectsGarbageCollector(nil))
+}
+
+func Test_jvmArgKey(t *testing.T) {
+	assert.Equal(t, "-Dnexus.foo", jvmArgKey("-Dnexus.foo=bar=baz"))
+	assert.Equal(t, "-XX:MaxDirectMemorySize", jvmArgKey("-XX:MaxDirectMemorySize=1024m"))
+	assert.Equal(t, "-XX:UseG1GC", jvmArgKey("-XX:+UseG1GC"))
+	assert.Equal(t, jvmArgKey("-XX:+UseContainerSupport"), jvmArgKey("-XX:-UseContainerSupport"))
+}
+
+func Test_newDeployment_customEnvAndVolumes(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec: v1alpha1.NexusSpec{
+			Replicas:       1,
+			Persistence:    v1alpha1.NexusPersistence{Persistent: true},
+			LivenessProbe:  validation.DefaultProbe,
+			ReadinessProbe: validation.DefaultProbe,
+			Env: []corev1.EnvVar{
+				{Name: "TZ", Value: "UTC"},
+				{Name: JvmArgsEnvKey, Value: "-Xmx10g"},
+			},
+			EnvFrom:      []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}}}},
+			Volumes:      []corev1.Volume{{Name: "plugins", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+			VolumeMounts: []corev1.VolumeMount{{Name: "plugins", MountPath: "/opt/sonatype/nexus/deploy"}},
+		},
+	}
+	deployment := newDeployment(nexus)
+	container := deployment.Spec.Template.Spec.Containers[0]
+
+	// the operator managed entries come first
+	assert.Len(t, container.Env, 2)
+	assert.Equal(t, JvmArgsEnvKey, container.Env[0].Name)
+	assert.NotContains(t, container.Env[0].Value, "-Xmx10g")
+	assert.Equal(t, "TZ", container.Env[1].Name)
+	assert.Equal(t, nexus.Spec.EnvFrom, container.EnvFrom)
+	assert.Len(t, deployment.Spec.Template.Spec.Volumes, 2)
+	assert.Equal(t, "plugins", deployment.Spec.Template.Spec.Volumes[1].Name)
+	assert.Len(t, container.VolumeMounts, 2)
+	assert.Equal(t, nexusDataDir, container.VolumeMounts[0].MountPath)
+	assert.Equal(t, "/opt/sonatype/nexus/deploy", container.VolumeMounts[1].MountPath)
+}
+
+func Test_newDeployment_sidecarsAndInitContainers(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec: v1alpha1.NexusSpec{
+			Replicas:       1,
+			LivenessProbe:  validation.DefaultProbe,
+			ReadinessProbe: validation.DefaultProbe,
+			Sidecars:       []corev1.Container{{Name: "log-shipper", Image: "fluent-bit"}},
+			InitContainers: []corev1.Container{{Name: "fix-permissions", Image: "busybox"}},
+		},
+	}
+	deployment := newDeployment(nexus)
+
+	assert.Len(t, deployment.Spec.Template.Spec.Containers, 2)
+	// the Nexus container must stay at index 0
+	assert.Equal(t, nexusContainerName, deployment.Spec.Template.Spec.Containers[0].Name)
+	assert.NotNil(t, deployment.Spec.Template.Spec.Containers[0].LivenessProbe)
+	assert.Equal(t, "log-shipper", deployment.Spec.Template.Spec.Containers[1].Name)
+	assert.Nil(t, deployment.Spec.Template.Spec.Containers[1].LivenessProbe)
+	assert.Empty(t, deployment.Spec.Template.Spec.Containers[1].Env)
+	assert.Equal(t, nexus.Spec.InitContainers, deployment.Spec.Template.Spec.InitContainers)
+}
+
+func Test_newDeployment_scheduling(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec: v1alpha1.NexusSpec{
+			Replicas:                  1,
+			LivenessProbe:             validation.DefaultProbe,
+			ReadinessProbe:            validation.DefaultProbe,
+			NodeSelector:              map[string]string{"disktype": "ssd"},
+			Tolerations:               []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "nexus", Effect: corev1.TaintEffectNoSchedule}},
+			Affinity:                  &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}},
+			TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: corev1.DoNotSchedule}},
+			PriorityClassName:         "high-priority",
+		},
+	}
+	podSpec := newDeployment(nexus).Spec.Template.Spec
+
+	assert.Equal(t, nexus.Spec.NodeSelector, podSpec.NodeSelector)
+	assert.Equal(t, nexus.Spec.Tolerations, podSpec.Tolerations)
+	assert.Equal(t, nexus.Spec.Affinity, podSpec.Affinity)
+	assert.Equal(t, nexus.Spec.TopologySpreadConstraints, podSpec.TopologySpreadConstraints)
+	assert.Equal(t, nexus.Spec.PriorityClassName, podSpec.PriorityClassName)
+}
+
+func Test_newDeployment_imagePullSecrets(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec: v1alpha1.NexusSpec{
+			Replicas:         1,
+			LivenessProbe:    validation.DefaultProbe,
+			ReadinessProbe:   validation.DefaultProbe,
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-credentials"}},
+		},
+	}
+	podSpec := newDeployment(nexus).Spec.Template.Spec
+
+	assert.Equal(t, nexus.Spec.ImagePullSecrets, podSpec.ImagePullSecrets)
+}
+
+func Test_newDeployment_customLabelsAndAnnotations(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec: v1alpha1.NexusSpec{
+			Replicas:       1,
+			LivenessProbe:  validation.DefaultProbe,
+			ReadinessProbe: validation.DefaultProbe,
+			// must not override the selector label
+			Labels:         map[string]string{"cost-center": "platform", meta.AppLabel: "other"},
+			Annotations:    map[string]string{"backup.example.com/enabled": "true"},
+			PodLabels:      map[string]string{"version": "3.25.1"},
+			PodAnnotations: map[string]string{"sidecar.istio.io/inject": "false"},
+		},
+	}
+	deployment := newDeployment(nexus)
+
+	assert.Equal(t, "nexus3", deployment.Labels[meta.AppLabel])
+	assert.Equal(t, "platform", deployment.Labels["cost-center"])
+	assert.NotContains(t, deployment.Labels, "version")
+	assert.Equal(t, nexus.Spec.Annotations, deployment.Annotations)
+	assert.Equal(t, "nexus3", deployment.Spec.Template.Labels[meta.AppLabel])
+	assert.Equal(t, "3.25.1", deployment.Spec.Template.Labels["version"])
+	assert.NotContains(t, deployment.Spec.Template.Labels, "cost-center")
+	assert.Equal(t, nexus.Spec.PodAnnotations, deployment.Spec.Template.Annotations)
+	assert.Equal(t, meta.GenerateLabels(nexus), deployment.Spec.Selector.MatchLabels)
+
+	svc := newService(nexus)
+	assert.Equal(t, deployment.Labels, svc.Labels)
+	assert.Equal(t, nexus.Spec.Annotations, svc.Annotations)
+	assert.Equal(t, meta.GenerateLabels(nexus), svc.Spec.Selector)
+}
+
+func Test_newDeployment_strategy(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec: v1alpha1.NexusSpec{
+			Replicas:       1,
+			LivenessProbe:  validation.DefaultProbe,
+			ReadinessProbe: validation.DefaultProbe,
+		},
+	}
+	// nothing to hold, the cluster default is fine
+	assert.Empty(t, newDeployment(nexus).Spec.Strategy.Type)
+
+	nexus.Spec.Persistence.Persistent = true
+	assert.Equal(t, appsv1.RecreateDeploymentStrategyType, newDeployment(nexus).Spec.Strategy.Type)
+
+	// ReadWriteMany volumes can be shared by the old and new pods
+	nexus.Spec.Replicas = 2
+	assert.Empty(t, newDeployment(nexus).Spec.Strategy.Type)
+
+	nexus.Spec.Replicas = 1
+	maxSurge := intstr.FromInt(0)
+	nexus.Spec.DeploymentStrategy = appsv1.DeploymentStrategy{
+		Type:          appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &maxSurge},
+	}
+	assert.Equal(t, nexus.Spec.DeploymentStrategy, newDeployment(nexus).Spec.Strategy)
+}
+
+func Test_applySecurityContext(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec: v1alpha1.NexusSpec{
+			Replicas:       1,
+			LivenessProbe:  validation.DefaultProbe,
+			ReadinessProbe: validation.DefaultProbe,
+		},
+	}
+	defaultContext := &corev1.PodSecurityContext{FSGroup: &nexusUID, RunAsUser: &nexusUID, SupplementalGroups: []int64{nexusUID}}
+
+	// defaults only
+	podSpec := newDeployment(nexus).Spec.Template.Spec
+	assert.Equal(t, defaultContext, podSpec.SecurityContext)
+	assert.Nil(t, podSpec.Containers[0].SecurityContext)
+
+	// the user informed fields override the defaults
+	uid := int64(1000680000)
+	nonRoot := true
+	nexus.Spec.SecurityContext = &corev1.PodSecurityContext{RunAsUser: &uid, RunAsNonRoot: &nonRoot}
+	readOnly := false
+	nexus.Spec.ContainerSecurityContext = &corev1.SecurityContext{
+		ReadOnlyRootFilesystem: &readOnly,
+		Capabilities:           &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+	}
+	nexus.Spec.Properties = map[string]string{"nexus.scripts.allowCreation": "true"}
+	nexus.Spec.InitContainers = []corev1.Container{{Name: "fix-permissions", Image: "busybox"}}
+	podSpec = newDeployment(nexus).Spec.Template.Spec
+	assert.Equal(t, &corev1.PodSecurityContext{FSGroup: &nexusUID, RunAsUser: &uid, RunAsNonRoot: &nonRoot, SupplementalGroups: []int64{nexusUID}}, podSpec.SecurityContext)
+	assert.Equal(t, nexus.Spec.ContainerSecurityContext, podSpec.Containers[0].SecurityContext)
+	// applied to the init containers created by the Operator, but not to the user informed ones
+	assert.Equal(t, propertiesInitContainerName, podSpec.InitContainers[0].Name)
+	assert.Equal(t, nexus.Spec.ContainerSecurityContext, podSpec.InitContainers[0].SecurityContext)
+	assert.Nil(t, podSpec.InitContainers[1].SecurityContext)
+	// the defaults must not be touched
+	assert.Equal(t, int64(200), nexusUID)
+
+	// no defaults for the Red Hat image
+	nexus.Spec.UseRedHatImage = true
+	podSpec = newDeployment(nexus).Spec.Template.Spec
+	assert.Equal(t, nexus.Spec.SecurityContext, podSpec.SecurityContext)
+	nexus.Spec.SecurityContext = nil
+	assert.Nil(t, newDeployment(nexus).Spec.Template.Spec.SecurityContext)
+}
+
+func Test_newDeployment_imageMirror(t *testing.T) {
+	_ = os.Setenv(update.ImageMirrorsEnvKey, "docker.io/sonatype/nexus3=registry.corp/sonatype/nexus3")
+	defer os.Unsetenv(update.ImageMirrorsEnvKey)
+
+	nexus := allDefaultsCommunityNexus.DeepCopy()
+	nexus.Spec.Image = validation.NexusCommunityImage + ":3.25.0"
+	nexus.Spec.Properties = map[string]string{"nexus.scripts.allowCreation": "true"}
+	podSpec := newDeployment(nexus).Spec.Template.Spec
+	assert.Equal(t, "registry.corp/sonatype/nexus3:3.25.0", podSpec.Containers[0].Image)
+	assert.Equal(t, "registry.corp/sonatype/nexus3:3.25.0", podSpec.InitContainers[0].Image)
+	// the Nexus CR is left as informed
+	assert.Equal(t, validation.NexusCommunityImage+":3.25.0", nexus.Spec.Image)
+}
+
+func TestReplicas(t *testing.T) {
+	nexus := &v1alpha1.Nexus{Spec: v1alpha1.NexusSpec{Replicas: 1, Maintenance: true}}
+	// frozen, but not scaling down
+	nexus.Status.MaintenanceFrozen = true
+	assert.Equal(t, int32(1), *Replicas(nexus))
+
+	// waiting for the freeze before scaling down
+	nexus.Spec.ScaleDownOnMaintenance = true
+	nexus.Status.MaintenanceFrozen = false
+	assert.Equal(t, int32(1), *Replicas(nexus))
+
+	nexus.Status.MaintenanceFrozen = true
+	assert.Equal(t, int32(0), *Replicas(nexus))
+
+	// leaving maintenance scales back up before releasing the freeze
+	nexus.Spec.Maintenance = false
+	assert.Equal(t, int32(1), *Replicas(nexus))
+}
+
+func Test_addExtraPorts(t *testing.T) {
+	nexus := allDefaultsCommunityNexus.DeepCopy()
+	nexus.Spec.ExtraPorts = []v1alpha1.NexusPort{{Name: "docker", Port: 8082, Protocol: corev1.ProtocolTCP}}
+	// the connectors found in the server don't restart the pods
+	nexus.Spec.ServerOperations.ExposeDockerConnectors = true
+	nexus.Status.ServerOperationsStatus.DockerConnectorPorts = []v1alpha1.NexusPort{{Name: "docker-group", Port: 8083, Protocol: corev1.ProtocolTCP}}
+
+	ports := newDeployment(nexus).Spec.Template.Spec.Containers[0].Ports
+	assert.Len(t, ports, 2)
+	assert.Equal(t, corev1.ContainerPort{Name: "docker", ContainerPort: 8082, Protocol: corev1.ProtocolTCP}, ports[1])
+}
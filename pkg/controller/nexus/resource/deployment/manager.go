@@ -26,10 +26,14 @@ import (
 	"github.com/m88i/nexus-operator/pkg/framework"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// deploymentRevisionAnnotation is set by the Deployment controller on every rollout
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
 var managedObjectsRef = map[string]resource.KubernetesResource{
 	"Service":    &corev1.Service{},
 	"Deployment": &appsv1.Deployment{},
@@ -53,7 +57,28 @@ func NewManager(nexus *v1alpha1.Nexus, client client.Client) *Manager {
 
 // GetRequiredResources returns the resources initialized by the manager
 func (m *Manager) GetRequiredResources() ([]resource.KubernetesResource, error) {
-	return []resource.KubernetesResource{newDeployment(m.nexus), newService(m.nexus)}, nil
+	resources := []resource.KubernetesResource{newService(m.nexus)}
+	// the pods are run by the StatefulSet manager otherwise
+	if m.nexus.Spec.WorkloadType != v1alpha1.StatefulSetWorkloadType {
+		deployment := newDeployment(m.nexus)
+		if err := m.addConfigChecksum(deployment); err != nil {
+			return nil, err
+		}
+		resources = append(resources, deployment)
+	}
+	if len(m.nexus.Spec.Properties) > 0 {
+		resources = append(resources, newPropertiesConfigMap(m.nexus))
+	}
+	return resources, nil
+}
+
+// NewPodTemplate creates the Nexus pod template, so other kinds of workload can run the same pods as the Deployment
+func NewPodTemplate(nexus *v1alpha1.Nexus, client client.Client) (corev1.PodTemplateSpec, error) {
+	deployment := newDeployment(nexus)
+	if err := NewManager(nexus, client).addConfigChecksum(deployment); err != nil {
+		return corev1.PodTemplateSpec{}, err
+	}
+	return deployment.Spec.Template, nil
 }
 
 // GetDeployedResources returns the deployment-related resources deployed on the cluster
@@ -66,6 +91,13 @@ func (m *Manager) GetDeployedResources() ([]resource.KubernetesResource, error)
 			return nil, fmt.Errorf("could not fetch Resource %s (%s): %v", resType, m.nexus.Name, err)
 		}
 	}
+	// the properties ConfigMap doesn't share the instance name
+	configMap := &corev1.ConfigMap{}
+	if err := framework.Fetch(m.client, propertiesConfigMapKey(m.nexus), configMap); err == nil {
+		resources = append(resources, configMap)
+	} else if !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("could not fetch Resource ConfigMap (%s): %v", propertiesConfigMapName(m.nexus), err)
+	}
 	return resources, nil
 }
 
@@ -75,6 +107,12 @@ func (m *Manager) GetCustomComparator(t reflect.Type) func(deployed resource.Kub
 	if t == reflect.TypeOf(&appsv1.Deployment{}) {
 		return deploymentEqual
 	}
+	if t == reflect.TypeOf(&corev1.ConfigMap{}) {
+		return configMapEqual
+	}
+	if t == reflect.TypeOf(&corev1.Service{}) {
+		return serviceEqual
+	}
 	return nil
 }
 
@@ -82,8 +120,12 @@ func (m *Manager) GetCustomComparator(t reflect.Type) func(deployed resource.Kub
 // Returns nil if there are none
 func (m *Manager) GetCustomComparators() map[reflect.Type]func(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
 	deploymentType := reflect.TypeOf(appsv1.Deployment{})
+	configMapType := reflect.TypeOf(corev1.ConfigMap{})
+	serviceType := reflect.TypeOf(corev1.Service{})
 	return map[reflect.Type]func(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool{
 		deploymentType: deploymentEqual,
+		configMapType:  configMapEqual,
+		serviceType:    serviceEqual,
 	}
 }
 
@@ -94,34 +136,124 @@ func deploymentEqual(deployed resource.KubernetesResource, requested resource.Ku
 	pairs = append(pairs, [2]interface{}{depDeployment.Name, reqDeployment.Name})
 	pairs = append(pairs, [2]interface{}{depDeployment.Namespace, reqDeployment.Namespace})
 	pairs = append(pairs, [2]interface{}{depDeployment.Labels, reqDeployment.Labels})
+	pairs = append(pairs, [2]interface{}{userAnnotations(depDeployment), reqDeployment.Annotations})
 	pairs = append(pairs, [2]interface{}{depDeployment.Spec.Replicas, reqDeployment.Spec.Replicas})
 	pairs = append(pairs, [2]interface{}{depDeployment.Spec.Selector, reqDeployment.Spec.Selector})
-	pairs = append(pairs, [2]interface{}{depDeployment.Spec.Template.ObjectMeta, reqDeployment.Spec.Template.ObjectMeta})
-	pairs = append(pairs, [2]interface{}{depDeployment.Spec.Template.Spec.Volumes, reqDeployment.Spec.Template.Spec.Volumes})
-	pairs = append(pairs, [2]interface{}{depDeployment.Spec.Template.Spec.ServiceAccountName, reqDeployment.Spec.Template.Spec.ServiceAccountName})
-	pairs = append(pairs, [2]interface{}{depDeployment.Spec.Template.Spec.SecurityContext, reqDeployment.Spec.Template.Spec.SecurityContext})
-	pairs = append(pairs, [2]interface{}{depDeployment.Spec.Template.Spec.Containers[0].Name, reqDeployment.Spec.Template.Spec.Containers[0].Name})
-	pairs = append(pairs, [2]interface{}{depDeployment.Spec.Template.Spec.Containers[0].Ports, reqDeployment.Spec.Template.Spec.Containers[0].Ports})
-	pairs = append(pairs, [2]interface{}{depDeployment.Spec.Template.Spec.Containers[0].Resources, reqDeployment.Spec.Template.Spec.Containers[0].Resources})
-	pairs = append(pairs, [2]interface{}{depDeployment.Spec.Template.Spec.Containers[0].Image, reqDeployment.Spec.Template.Spec.Containers[0].Image})
-	pairs = append(pairs, [2]interface{}{depDeployment.Spec.Template.Spec.Containers[0].LivenessProbe, reqDeployment.Spec.Template.Spec.Containers[0].LivenessProbe})
-	pairs = append(pairs, [2]interface{}{depDeployment.Spec.Template.Spec.Containers[0].ReadinessProbe, reqDeployment.Spec.Template.Spec.Containers[0].ReadinessProbe})
-	pairs = append(pairs, [2]interface{}{depDeployment.Spec.Template.Spec.Containers[0].Env, reqDeployment.Spec.Template.Spec.Containers[0].Env})
+
+	// these might contain user informed structures which are defaulted by the cluster
+	var derivativePairs [][2]interface{}
+	derivativePairs = append(derivativePairs, [2]interface{}{depDeployment.Spec.Strategy, reqDeployment.Spec.Strategy})
+
+	equal := compare.EqualPairs(pairs)
+	equal = equal && equalDerivativePairs(derivativePairs)
+	equal = equal && PodTemplateEqual(&depDeployment.Spec.Template, &reqDeployment.Spec.Template)
+	return equal
+}
+
+// PodTemplateEqual compares the Nexus pod templates, ignoring the fields defaulted by the cluster
+func PodTemplateEqual(deployed, requested *corev1.PodTemplateSpec) bool {
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{deployed.ObjectMeta, requested.ObjectMeta})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.ServiceAccountName, requested.Spec.ServiceAccountName})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.ImagePullSecrets, requested.Spec.ImagePullSecrets})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.SecurityContext, requested.Spec.SecurityContext})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.NodeSelector, requested.Spec.NodeSelector})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.PriorityClassName, requested.Spec.PriorityClassName})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.Affinity, requested.Spec.Affinity})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.Containers[0].Name, requested.Spec.Containers[0].Name})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.Containers[0].Ports, requested.Spec.Containers[0].Ports})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.Containers[0].Resources, requested.Spec.Containers[0].Resources})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.Containers[0].Image, requested.Spec.Containers[0].Image})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.Containers[0].LivenessProbe, requested.Spec.Containers[0].LivenessProbe})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.Containers[0].ReadinessProbe, requested.Spec.Containers[0].ReadinessProbe})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.Containers[0].StartupProbe, requested.Spec.Containers[0].StartupProbe})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.Containers[0].SecurityContext, requested.Spec.Containers[0].SecurityContext})
+	pairs = append(pairs, [2]interface{}{deployed.Spec.Containers[0].Lifecycle, requested.Spec.Containers[0].Lifecycle})
+
+	// these might contain user informed structures which are defaulted by the cluster (e.g. a ConfigMap volume's defaultMode),
+	// so we only compare the fields we actually set
+	var derivativePairs [][2]interface{}
+	derivativePairs = append(derivativePairs, [2]interface{}{deployed.Spec.Volumes, requested.Spec.Volumes})
+	derivativePairs = append(derivativePairs, [2]interface{}{deployed.Spec.Containers[0].VolumeMounts, requested.Spec.Containers[0].VolumeMounts})
+	derivativePairs = append(derivativePairs, [2]interface{}{deployed.Spec.Containers[0].Env, requested.Spec.Containers[0].Env})
+	derivativePairs = append(derivativePairs, [2]interface{}{deployed.Spec.Containers[0].EnvFrom, requested.Spec.Containers[0].EnvFrom})
+	// sidecars
+	derivativePairs = append(derivativePairs, [2]interface{}{deployed.Spec.Containers[1:], requested.Spec.Containers[1:]})
+	derivativePairs = append(derivativePairs, [2]interface{}{deployed.Spec.InitContainers, requested.Spec.InitContainers})
+	derivativePairs = append(derivativePairs, [2]interface{}{deployed.Spec.Tolerations, requested.Spec.Tolerations})
+	derivativePairs = append(derivativePairs, [2]interface{}{deployed.Spec.TopologySpreadConstraints, requested.Spec.TopologySpreadConstraints})
+	// defaulted by the cluster when not informed
+	derivativePairs = append(derivativePairs, [2]interface{}{deployed.Spec.TerminationGracePeriodSeconds, requested.Spec.TerminationGracePeriodSeconds})
 
 	equal := compare.EqualPairs(pairs)
-	equal = equal && equalPullPolicies(depDeployment, reqDeployment)
+	equal = equal && equalDerivativePairs(derivativePairs)
+	equal = equal && equalPullPolicies(deployed, requested)
 	return equal
 }
 
-func equalPullPolicies(depDeployment, reqDeployment *appsv1.Deployment) bool {
-	if len(reqDeployment.Spec.Template.Spec.Containers[0].ImagePullPolicy) > 0 {
-		return reqDeployment.Spec.Template.Spec.Containers[0].ImagePullPolicy == depDeployment.Spec.Template.Spec.Containers[0].ImagePullPolicy
+// equalDerivativePairs checks if each deployed object is equal to its requested counterpart, ignoring fields unset in the requested one.
+// Slices must still have the same length and be both nil or not, so removing an element is also detected.
+func equalDerivativePairs(pairs [][2]interface{}) bool {
+	for _, pair := range pairs {
+		deployed, requested := reflect.ValueOf(pair[0]), reflect.ValueOf(pair[1])
+		if deployed.Kind() == reflect.Slice && (deployed.Len() != requested.Len() || deployed.IsNil() != requested.IsNil()) {
+			return false
+		}
+		if !equality.Semantic.DeepDerivative(pair[1], pair[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+func configMapEqual(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
+	depConfigMap := deployed.(*corev1.ConfigMap)
+	reqConfigMap := requested.(*corev1.ConfigMap)
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{depConfigMap.Name, reqConfigMap.Name})
+	pairs = append(pairs, [2]interface{}{depConfigMap.Namespace, reqConfigMap.Namespace})
+	pairs = append(pairs, [2]interface{}{depConfigMap.Labels, reqConfigMap.Labels})
+	pairs = append(pairs, [2]interface{}{depConfigMap.Annotations, reqConfigMap.Annotations})
+	pairs = append(pairs, [2]interface{}{depConfigMap.Data, reqConfigMap.Data})
+	return compare.EqualPairs(pairs)
+}
+
+// serviceEqual compares the Services like the default comparator does, ignoring the node ports allocated by the cluster
+// to the extra ports when exposing the instance as NodePort
+func serviceEqual(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
+	depService := deployed.(*corev1.Service).DeepCopy()
+	reqService := requested.(*corev1.Service)
+	for i := range depService.Spec.Ports {
+		if i < len(reqService.Spec.Ports) && reqService.Spec.Ports[i].NodePort == 0 {
+			depService.Spec.Ports[i].NodePort = 0
+		}
 	}
+	return compare.DefaultComparator().GetComparator(reflect.TypeOf(corev1.Service{}))(depService, reqService)
+}
 
-	reqImageParts := strings.Split(reqDeployment.Spec.Template.Spec.Containers[0].Image, ":")
+func equalPullPolicies(deployed, requested *corev1.PodTemplateSpec) bool {
+	if len(requested.Spec.Containers[0].ImagePullPolicy) > 0 {
+		return requested.Spec.Containers[0].ImagePullPolicy == deployed.Spec.Containers[0].ImagePullPolicy
+	}
+
+	reqImageParts := strings.Split(requested.Spec.Containers[0].Image, ":")
 	if len(reqImageParts) == 1 || reqImageParts[1] == "latest" {
-		return depDeployment.Spec.Template.Spec.Containers[0].ImagePullPolicy == corev1.PullAlways
+		return deployed.Spec.Containers[0].ImagePullPolicy == corev1.PullAlways
 	}
 
-	return depDeployment.Spec.Template.Spec.Containers[0].ImagePullPolicy == corev1.PullIfNotPresent
+	return deployed.Spec.Containers[0].ImagePullPolicy == corev1.PullIfNotPresent
+}
+
+// userAnnotations returns the Deployment annotations without the ones managed by the cluster
+func userAnnotations(deployment *appsv1.Deployment) map[string]string {
+	annotations := map[string]string{}
+	for key, value := range deployment.Annotations {
+		if key != deploymentRevisionAnnotation {
+			annotations[key] = value
+		}
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
 }
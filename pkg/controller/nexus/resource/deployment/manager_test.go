@@ -28,6 +28,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 var (
@@ -40,6 +41,7 @@ var (
 			Image:              validation.NexusCommunityImage,
 			LivenessProbe:      validation.DefaultProbe.DeepCopy(),
 			ReadinessProbe:     validation.DefaultProbe.DeepCopy(),
+			StartupProbe:       validation.DefaultStartupProbe.DeepCopy(),
 		},
 	}
 )
@@ -72,6 +74,22 @@ func TestManager_GetRequiredResources(t *testing.T) {
 	assert.Len(t, resources, 2)
 	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&corev1.Service{})))
 	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&appsv1.Deployment{})))
+
+	// the properties ConfigMap is only created when there are properties to write
+	mgr.nexus = allDefaultsCommunityNexus.DeepCopy()
+	mgr.nexus.Spec.Properties = map[string]string{"nexus.scripts.allowCreation": "true"}
+	resources, err = mgr.GetRequiredResources()
+	assert.Nil(t, err)
+	assert.Len(t, resources, 3)
+	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&corev1.ConfigMap{})))
+
+	// the pods are run by a StatefulSet instead, but the service is still needed
+	mgr.nexus = allDefaultsCommunityNexus.DeepCopy()
+	mgr.nexus.Spec.WorkloadType = v1alpha1.StatefulSetWorkloadType
+	resources, err = mgr.GetRequiredResources()
+	assert.Nil(t, err)
+	assert.Len(t, resources, 1)
+	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&corev1.Service{})))
 }
 
 func TestManager_GetDeployedResources(t *testing.T) {
@@ -101,6 +119,13 @@ func TestManager_GetDeployedResources(t *testing.T) {
 	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&corev1.Service{})))
 	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&appsv1.Deployment{})))
 
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: propertiesConfigMapName(mgr.nexus), Namespace: mgr.nexus.Namespace}}
+	assert.NoError(t, mgr.client.Create(ctx.TODO(), configMap))
+	resources, err = mgr.GetDeployedResources()
+	assert.Nil(t, err)
+	assert.Len(t, resources, 3)
+	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&corev1.ConfigMap{})))
+
 	// make the client return a mocked 500 response to test errors other than NotFound
 	mockErrorMsg := "mock 500"
 	fakeClient.SetMockErrorForOneRequest(errors.NewInternalError(fmt.Errorf(mockErrorMsg)))
@@ -114,11 +139,13 @@ func TestManager_GetCustomComparator(t *testing.T) {
 	// comparator functions offered by the manager
 	mgr := &Manager{}
 
-	// there is a custom comparator function for deployments, but not services
+	// there are custom comparator functions for deployments and services
 	deploymentComp := mgr.GetCustomComparator(reflect.TypeOf(&appsv1.Deployment{}))
 	assert.NotNil(t, deploymentComp)
 	svcComp := mgr.GetCustomComparator(reflect.TypeOf(&corev1.Service{}))
-	assert.Nil(t, svcComp)
+	assert.NotNil(t, svcComp)
+	configMapComp := mgr.GetCustomComparator(reflect.TypeOf(&corev1.ConfigMap{}))
+	assert.NotNil(t, configMapComp)
 }
 
 func TestManager_GetCustomComparators(t *testing.T) {
@@ -126,9 +153,31 @@ func TestManager_GetCustomComparators(t *testing.T) {
 	// comparator functions offered by the manager
 	mgr := &Manager{}
 
-	// there is a custom comparator for deployments
+	// there are custom comparators for deployments, config maps and services
 	comparators := mgr.GetCustomComparators()
-	assert.Len(t, comparators, 1)
+	assert.Len(t, comparators, 3)
+}
+
+func Test_serviceEqual(t *testing.T) {
+	nexus := allDefaultsCommunityNexus.DeepCopy()
+	nexus.Spec.Networking = v1alpha1.NexusNetworking{Expose: true, ExposeAs: v1alpha1.NodePortExposeType, NodePort: 31031}
+	nexus.Spec.ExtraPorts = []v1alpha1.NexusPort{{Name: "docker", Port: 8082, Protocol: corev1.ProtocolTCP}}
+	requested := newService(nexus)
+
+	// the node port of the extra port is allocated by the cluster
+	deployed := requested.DeepCopy()
+	deployed.Spec.ClusterIP = "10.0.0.1"
+	deployed.Spec.Ports[1].NodePort = 30082
+	assert.True(t, serviceEqual(deployed, requested))
+
+	// the informed node port is still compared
+	deployed.Spec.Ports[0].NodePort = 31032
+	assert.False(t, serviceEqual(deployed, requested))
+
+	// the extra port was removed
+	nexus.Spec.ExtraPorts = nil
+	deployed = requested.DeepCopy()
+	assert.False(t, serviceEqual(deployed, newService(nexus)))
 }
 
 func Test_deploymentEqual(t *testing.T) {
@@ -237,6 +286,40 @@ func Test_deploymentEqual(t *testing.T) {
 			baseDeployment.DeepCopy(),
 			false,
 		},
+		{
+			"Different container security context",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				readOnly := true
+				d.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{ReadOnlyRootFilesystem: &readOnly}
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"Different termination grace period",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				gracePeriod := int64(300)
+				d.Spec.Template.Spec.TerminationGracePeriodSeconds = &gracePeriod
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"PreStop hook removed",
+			baseDeployment.DeepCopy(),
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.Containers[0].Lifecycle = &corev1.Lifecycle{
+					PreStop: &corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", freezeScript(allDefaultsCommunityNexus)}}},
+				}
+				return d
+			}(),
+			false,
+		},
 		{
 			"Different container name",
 			func() *appsv1.Deployment {
@@ -297,6 +380,16 @@ func Test_deploymentEqual(t *testing.T) {
 			baseDeployment.DeepCopy(),
 			false,
 		},
+		{
+			"Different container startup probe",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.Containers[0].StartupProbe = nil
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
 		{
 			"Different container env",
 			func() *appsv1.Deployment {
@@ -308,13 +401,191 @@ func Test_deploymentEqual(t *testing.T) {
 			false,
 		},
 		{
-			"Different field we don't care about (deployment strategy)",
+			"Different container volume mounts",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{{Name: "plugins", MountPath: "/opt/sonatype/nexus/deploy"}}
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"Different container env from",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.Containers[0].EnvFrom = []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}}}}
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"Different sidecars",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, corev1.Container{Name: "log-shipper", Image: "fluent-bit"})
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"Different init containers",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "fix-permissions", Image: "busybox"}}
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"Sidecar fields defaulted by the cluster",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, corev1.Container{Name: "log-shipper", Image: "fluent-bit"})
+				return d
+			}(),
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers,
+					corev1.Container{Name: "log-shipper", Image: "fluent-bit", TerminationMessagePath: "/dev/termination-log", ImagePullPolicy: corev1.PullAlways})
+				return d
+			}(),
+			true,
+		},
+		{
+			"Volume fields defaulted by the cluster",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.Volumes = []corev1.Volume{{Name: "ca", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}}}}}
+				return d
+			}(),
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				defaultMode := int32(420)
+				d.Spec.Template.Spec.Volumes = []corev1.Volume{{Name: "ca", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}, DefaultMode: &defaultMode}}}}
+				return d
+			}(),
+			true,
+		},
+		{
+			"Different node selector",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.NodeSelector = map[string]string{"disktype": "ssd"}
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"Different tolerations",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "nexus", Effect: corev1.TaintEffectNoSchedule}}
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"Different affinity",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"Different topology spread constraints",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: corev1.DoNotSchedule}}
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"Different priority class name",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.PriorityClassName = "high-priority"
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"Different image pull secrets",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry-credentials"}}
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"Different annotations",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Annotations = map[string]string{"backup.example.com/enabled": "true"}
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"Different pod annotations",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Template.Annotations = map[string]string{"sidecar.istio.io/inject": "false"}
+				return d
+			}(),
+			baseDeployment.DeepCopy(),
+			false,
+		},
+		{
+			"Revision annotation set by the cluster",
+			baseDeployment.DeepCopy(),
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Annotations = map[string]string{deploymentRevisionAnnotation: "3"}
+				return d
+			}(),
+			true,
+		},
+		{
+			"Different deployment strategy",
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				d.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+				return d
+			}(),
 			func() *appsv1.Deployment {
 				d := baseDeployment.DeepCopy()
 				d.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType}
 				return d
 			}(),
+			false,
+		},
+		{
+			"Deployment strategy defaulted by the cluster",
 			baseDeployment.DeepCopy(),
+			func() *appsv1.Deployment {
+				d := baseDeployment.DeepCopy()
+				maxUnavailable := intstr.FromString("25%")
+				d.Spec.Strategy = appsv1.DeploymentStrategy{
+					Type:          appsv1.RollingUpdateDeploymentStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDeployment{MaxUnavailable: &maxUnavailable, MaxSurge: &maxUnavailable},
+				}
+				return d
+			}(),
 			true,
 		},
 	}
@@ -333,26 +604,26 @@ func Test_equalPullPolicies(t *testing.T) {
 
 	// first let's test an unspecified pull policy with no tag
 	depDeployment.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullAlways
-	assert.True(t, equalPullPolicies(depDeployment, reqDeployment))
+	assert.True(t, equalPullPolicies(&depDeployment.Spec.Template, &reqDeployment.Spec.Template))
 	depDeployment.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
-	assert.False(t, equalPullPolicies(depDeployment, reqDeployment))
+	assert.False(t, equalPullPolicies(&depDeployment.Spec.Template, &reqDeployment.Spec.Template))
 
 	// now let's set the latest tag on the images so we can test for the PullAlways pull policy in that scenario as well
 	depDeployment.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullAlways
 	reqDeployment.Spec.Template.Spec.Containers[0].Image = fmt.Sprintf("%s:%s", validation.NexusCommunityImage, "latest")
-	assert.True(t, equalPullPolicies(depDeployment, reqDeployment))
+	assert.True(t, equalPullPolicies(&depDeployment.Spec.Template, &reqDeployment.Spec.Template))
 
 	// now with an actual tag and empty pullPolicy on the required deployment
 	reqDeployment.Spec.Template.Spec.Containers[0].Image = fmt.Sprintf("%s:%s", validation.NexusCommunityImage, "3.25.0")
 	depDeployment.Spec.Template.Spec.Containers[0].Image = fmt.Sprintf("%s:%s", validation.NexusCommunityImage, "3.25.0")
 	depDeployment.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
-	assert.True(t, equalPullPolicies(depDeployment, reqDeployment))
+	assert.True(t, equalPullPolicies(&depDeployment.Spec.Template, &reqDeployment.Spec.Template))
 
 	// with the same pull policies
 	reqDeployment.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
-	assert.True(t, equalPullPolicies(depDeployment, reqDeployment))
+	assert.True(t, equalPullPolicies(&depDeployment.Spec.Template, &reqDeployment.Spec.Template))
 
 	// with different pull policies
 	depDeployment.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullAlways
-	assert.False(t, equalPullPolicies(depDeployment, reqDeployment))
+	assert.False(t, equalPullPolicies(&depDeployment.Spec.Template, &reqDeployment.Spec.Template))
 }
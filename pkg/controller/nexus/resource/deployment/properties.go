@@ -0,0 +1,166 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/update"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// PropertiesChecksumAnnotation holds the checksum of the server properties in the pod template, so changing them triggers a rollout
+	PropertiesChecksumAnnotation = "apps.m88i.io/properties-checksum"
+
+	propertiesFileName          = "nexus.properties"
+	propertiesMountPath         = "/nexus-properties"
+	propertiesInitContainerName = "nexus-properties"
+)
+
+var (
+	propertiesFile = path.Join(nexusDataDir, "etc", propertiesFileName)
+	// propertiesMarkerFile is written next to the properties file, so only a file delivered by the Operator is removed
+	propertiesMarkerFile = path.Join(nexusDataDir, "etc", ".operator-"+propertiesFileName)
+)
+
+func propertiesConfigMapName(nexus *v1alpha1.Nexus) string {
+	return fmt.Sprintf("%s-properties", nexus.Name)
+}
+
+func propertiesConfigMapKey(nexus *v1alpha1.Nexus) types.NamespacedName {
+	return types.NamespacedName{Namespace: nexus.Namespace, Name: propertiesConfigMapName(nexus)}
+}
+
+func newPropertiesConfigMap(nexus *v1alpha1.Nexus) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: meta.DefaultObjectMeta(nexus),
+		Data:       map[string]string{propertiesFileName: renderProperties(nexus.Spec.Properties)},
+	}
+	configMap.Name = propertiesConfigMapName(nexus)
+	return configMap
+}
+
+// addProperties delivers the server properties into the data directory with an init container, since the server
+// must be able to write to its configuration directory
+func addProperties(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
+	if len(nexus.Spec.Properties) == 0 {
+		removeProperties(nexus, deployment)
+		return
+	}
+	dataVolume := DataVolumeName(nexus)
+	propertiesVolume := propertiesConfigMapName(nexus)
+	podSpec := &deployment.Spec.Template.Spec
+
+	if !nexus.Spec.Persistence.Persistent {
+		// the init container needs a volume to share the data directory with the server
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name:         dataVolume,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: dataVolume, MountPath: nexusDataDir})
+	}
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: propertiesVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: propertiesConfigMapName(nexus)}},
+		},
+	})
+
+	container := newPropertiesInitContainer(nexus,
+		fmt.Sprintf("mkdir -p %s && cp %s %s && touch %s",
+			path.Dir(propertiesFile), path.Join(propertiesMountPath, propertiesFileName), propertiesFile, propertiesMarkerFile))
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: propertiesVolume, MountPath: propertiesMountPath, ReadOnly: true})
+	podSpec.InitContainers = append(podSpec.InitContainers, container)
+
+	if deployment.Spec.Template.Annotations == nil {
+		deployment.Spec.Template.Annotations = map[string]string{}
+	}
+	deployment.Spec.Template.Annotations[PropertiesChecksumAnnotation] =
+		fmt.Sprintf("%x", sha256.Sum256([]byte(renderProperties(nexus.Spec.Properties))))
+}
+
+// removeProperties deletes the properties file previously delivered to a persistent data directory, so the server
+// doesn't keep reading properties that are no longer informed. Files not written by the Operator are left untouched.
+func removeProperties(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
+	if !nexus.Spec.Persistence.Persistent {
+		// the data directory is gone with the previous pod
+		return
+	}
+	deployment.Spec.Template.Spec.InitContainers = append(deployment.Spec.Template.Spec.InitContainers,
+		newPropertiesInitContainer(nexus,
+			fmt.Sprintf("if [ -f %s ]; then rm -f %s %s; fi", propertiesMarkerFile, propertiesFile, propertiesMarkerFile)))
+}
+
+// newPropertiesInitContainer returns the init container running the given command against the data directory.
+// The server image is used, so there's no need to pull another one and the file is handled by the same user.
+func newPropertiesInitContainer(nexus *v1alpha1.Nexus, command string) corev1.Container {
+	return corev1.Container{
+		Name:            propertiesInitContainerName,
+		Image:           update.MirroredImage(nexus.Spec.Image),
+		ImagePullPolicy: nexus.Spec.ImagePullPolicy,
+		Command:         []string{"sh", "-c", command},
+		Resources:       nexus.Spec.Resources,
+		VolumeMounts:    []corev1.VolumeMount{{Name: DataVolumeName(nexus), MountPath: nexusDataDir}},
+	}
+}
+
+// renderProperties writes the given properties in the Java properties file format, sorted by key
+func renderProperties(properties map[string]string) string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var rendered strings.Builder
+	for _, key := range keys {
+		rendered.WriteString(fmt.Sprintf("%s=%s\n", escapeProperty(key, true), escapeProperty(properties[key], false)))
+	}
+	return rendered.String()
+}
+
+func escapeProperty(value string, isKey bool) string {
+	var escaped strings.Builder
+	for i, r := range value {
+		switch {
+		case r == '\\':
+			escaped.WriteString(`\\`)
+		case r == '\n':
+			escaped.WriteString(`\n`)
+		case r == '\r':
+			escaped.WriteString(`\r`)
+		case r == '\t':
+			escaped.WriteString(`\t`)
+		case r == ' ' && (isKey || i == 0):
+			// leading whitespaces are ignored in values, any whitespace ends a key
+			escaped.WriteString(`\ `)
+		case isKey && (r == '=' || r == ':'):
+			escaped.WriteRune('\\')
+			escaped.WriteRune(r)
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/validation"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_renderProperties(t *testing.T) {
+	properties := map[string]string{
+		"nexus.scripts.allowCreation": "true",
+		"application-port":            "8081",
+		"nexus.datastore.enabled":     "false",
+		"key with=separators:":        `C:\path`,
+		"leading.space":               " value",
+	}
+	assert.Equal(t, `application-port=8081
+key\ with\=separators\:=C:\\path
+leading.space=\ value
+nexus.datastore.enabled=false
+nexus.scripts.allowCreation=true
+`, renderProperties(properties))
+	assert.Empty(t, renderProperties(nil))
+}
+
+func Test_addProperties(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec: v1alpha1.NexusSpec{
+			Replicas:       1,
+			Image:          validation.NexusCommunityImage,
+			LivenessProbe:  validation.DefaultProbe,
+			ReadinessProbe: validation.DefaultProbe,
+			InitContainers: []corev1.Container{{Name: "fix-permissions", Image: "busybox"}},
+		},
+	}
+
+	// nothing to deliver
+	deployment := newDeployment(nexus)
+	assert.Len(t, deployment.Spec.Template.Spec.InitContainers, 1)
+	assert.NotContains(t, deployment.Spec.Template.Annotations, PropertiesChecksumAnnotation)
+
+	nexus.Spec.Properties = map[string]string{"nexus.scripts.allowCreation": "true"}
+	deployment = newDeployment(nexus)
+	podSpec := deployment.Spec.Template.Spec
+	// must run before the ones informed by the user
+	assert.Len(t, podSpec.InitContainers, 2)
+	assert.Equal(t, propertiesInitContainerName, podSpec.InitContainers[0].Name)
+	assert.Equal(t, nexus.Spec.Image, podSpec.InitContainers[0].Image)
+	// an ephemeral data volume is shared with the server when there's no persistence
+	assert.Equal(t, "nexus3-data", podSpec.Volumes[0].Name)
+	assert.NotNil(t, podSpec.Volumes[0].EmptyDir)
+	assert.Equal(t, propertiesConfigMapName(nexus), podSpec.Volumes[1].ConfigMap.Name)
+	assert.Equal(t, nexusDataDir, podSpec.Containers[0].VolumeMounts[0].MountPath)
+
+	checksum := deployment.Spec.Template.Annotations[PropertiesChecksumAnnotation]
+	assert.NotEmpty(t, checksum)
+	nexus.Spec.Properties["nexus.scripts.allowCreation"] = "false"
+	assert.NotEqual(t, checksum, newDeployment(nexus).Spec.Template.Annotations[PropertiesChecksumAnnotation])
+
+	// the persistent volume is used when available
+	nexus.Spec.Persistence.Persistent = true
+	podSpec = newDeployment(nexus).Spec.Template.Spec
+	assert.Len(t, podSpec.Volumes, 2)
+	assert.NotNil(t, podSpec.Volumes[0].PersistentVolumeClaim)
+	assert.Len(t, podSpec.Containers[0].VolumeMounts, 1)
+	assert.Contains(t, podSpec.InitContainers[0].Command[2], "touch "+propertiesMarkerFile)
+
+	// the file delivered before is removed from the persistent volume once the properties are gone
+	nexus.Spec.Properties = nil
+	deployment = newDeployment(nexus)
+	podSpec = deployment.Spec.Template.Spec
+	assert.Len(t, podSpec.InitContainers, 2)
+	assert.Equal(t, propertiesInitContainerName, podSpec.InitContainers[0].Name)
+	assert.Equal(t, []corev1.VolumeMount{{Name: "nexus3-data", MountPath: nexusDataDir}}, podSpec.InitContainers[0].VolumeMounts)
+	assert.Equal(t, []string{"sh", "-c", fmt.Sprintf("if [ -f %s ]; then rm -f %s %s; fi", propertiesMarkerFile, propertiesFile, propertiesMarkerFile)},
+		podSpec.InitContainers[0].Command)
+	assert.Len(t, podSpec.Volumes, 1)
+	assert.NotContains(t, deployment.Spec.Template.Annotations, PropertiesChecksumAnnotation)
+}
+
+func Test_newPropertiesConfigMap(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec:       v1alpha1.NexusSpec{Properties: map[string]string{"nexus.scripts.allowCreation": "true"}},
+	}
+	configMap := newPropertiesConfigMap(nexus)
+	assert.Equal(t, "nexus3-properties", configMap.Name)
+	assert.Equal(t, nexus.Namespace, configMap.Namespace)
+	assert.Equal(t, "nexus.scripts.allowCreation=true\n", configMap.Data[propertiesFileName])
+	assert.True(t, configMapEqual(configMap, newPropertiesConfigMap(nexus)))
+
+	nexus.Spec.Properties["nexus.scripts.allowCreation"] = "false"
+	assert.False(t, configMapEqual(configMap, newPropertiesConfigMap(nexus)))
+}
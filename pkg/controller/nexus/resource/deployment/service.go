@@ -45,6 +45,17 @@ func newService(nexus *v1alpha1.Nexus) *corev1.Service {
 		},
 	}
 
+	for _, port := range ExtraPorts(nexus) {
+		svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{
+			Name:     port.Name,
+			Protocol: port.Protocol,
+			Port:     port.Port,
+			TargetPort: intstr.IntOrString{
+				IntVal: port.Port,
+			},
+		})
+	}
+
 	if nexus.Spec.Networking.ExposeAs == v1alpha1.NodePortExposeType {
 		svc.Spec.Type = corev1.ServiceTypeNodePort
 		svc.Spec.Ports[0].NodePort = nexus.Spec.Networking.NodePort
@@ -53,3 +64,27 @@ func newService(nexus *v1alpha1.Nexus) *corev1.Service {
 
 	return svc
 }
+
+// ExtraPorts returns the ports served besides the Nexus one: the ones informed in 'spec.extraPorts' followed by
+// the Docker connectors found by the server operations, unless already informed
+func ExtraPorts(nexus *v1alpha1.Nexus) []v1alpha1.NexusPort {
+	ports := append([]v1alpha1.NexusPort{}, nexus.Spec.ExtraPorts...)
+	if !nexus.Spec.ServerOperations.ExposeDockerConnectors {
+		return ports
+	}
+	for _, connector := range nexus.Status.ServerOperationsStatus.DockerConnectorPorts {
+		if !hasPort(ports, connector) {
+			ports = append(ports, connector)
+		}
+	}
+	return ports
+}
+
+func hasPort(ports []v1alpha1.NexusPort, port v1alpha1.NexusPort) bool {
+	for _, p := range ports {
+		if p.Name == port.Name || p.Port == port.Port {
+			return true
+		}
+	}
+	return false
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -44,3 +45,29 @@ func Test_newService(t *testing.T) {
 	assert.Equal(t, appName, svc.Labels[meta.AppLabel])
 	assert.Equal(t, appName, svc.Spec.Selector[meta.AppLabel])
 }
+
+func Test_newService_extraPorts(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec: v1alpha1.NexusSpec{
+			ExtraPorts:       []v1alpha1.NexusPort{{Name: "docker", Port: 8082, Protocol: corev1.ProtocolTCP}},
+			ServerOperations: v1alpha1.ServerOperationsOpts{ExposeDockerConnectors: true},
+		},
+	}
+	nexus.Status.ServerOperationsStatus.DockerConnectorPorts = []v1alpha1.NexusPort{
+		// already informed
+		{Name: "docker-hosted", Port: 8082, Protocol: corev1.ProtocolTCP},
+		{Name: "docker-group", Port: 8083, Protocol: corev1.ProtocolTCP},
+	}
+	svc := newService(nexus)
+
+	assert.Len(t, svc.Spec.Ports, 3)
+	assert.Equal(t, "docker", svc.Spec.Ports[1].Name)
+	assert.Equal(t, int32(8082), svc.Spec.Ports[1].TargetPort.IntVal)
+	assert.Equal(t, "docker-group", svc.Spec.Ports[2].Name)
+	assert.Equal(t, int32(8083), svc.Spec.Ports[2].Port)
+
+	// the connectors found in the server are only exposed when asked to
+	nexus.Spec.ServerOperations.ExposeDockerConnectors = false
+	assert.Len(t, newService(nexus).Spec.Ports, 2)
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	freezePath                   = "/service/rest/v1/read-only/freeze"
+	operatorCredentialsMountPath = "/nexus-operator-credentials"
+)
+
+// freezeScript puts the server in read-only mode, authenticating as the operator user whose credentials are stored in the instance Secret.
+// The Secret is mounted instead of exposed as environment variables, so credentials created after the pod started are still picked up.
+// When the server serves HTTPS its certificate isn't issued for localhost, so it's not verified: the request never leaves the pod anyway.
+func freezeScript(nexus *v1alpha1.Nexus) string {
+	scheme, insecure := "http", ""
+	if nexus.Spec.ServerOperations.TLS.Enabled {
+		scheme, insecure = "https", "-k "
+	}
+	return fmt.Sprintf(`curl -sSf %s-X POST -u "$(cat %s):$(cat %s)" %s://localhost:%d%s`,
+		insecure,
+		path.Join(operatorCredentialsMountPath, meta.SecretKeyUsername),
+		path.Join(operatorCredentialsMountPath, meta.SecretKeyPassword),
+		scheme, NexusServicePort, freezePath)
+}
+
+// applyShutdown sets how long the server has to shut down and, if enabled, adds the preStop hook freezing the server
+// so its databases are flushed before it's stopped
+func applyShutdown(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
+	if nexus.Spec.TerminationGracePeriodSeconds != nil {
+		gracePeriod := *nexus.Spec.TerminationGracePeriodSeconds
+		deployment.Spec.Template.Spec.TerminationGracePeriodSeconds = &gracePeriod
+	}
+	if !nexus.Spec.FreezeOnShutdown {
+		return
+	}
+
+	credentialsVolume := fmt.Sprintf("%s-operator-credentials", nexus.Name)
+	optional := true
+	deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: credentialsVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: nexus.Name, Optional: &optional},
+		},
+	})
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      credentialsVolume,
+		MountPath: operatorCredentialsMountPath,
+		ReadOnly:  true,
+	})
+	container.Lifecycle = &corev1.Lifecycle{
+		PreStop: &corev1.Handler{
+			Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", freezeScript(nexus)}},
+		},
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_applyShutdown(t *testing.T) {
+	nexus := allDefaultsCommunityNexus.DeepCopy()
+	gracePeriod := int64(300)
+	nexus.Spec.TerminationGracePeriodSeconds = &gracePeriod
+	deployment := newDeployment(nexus)
+	podSpec := deployment.Spec.Template.Spec
+	assert.Equal(t, gracePeriod, *podSpec.TerminationGracePeriodSeconds)
+	// the pod doesn't share the value from the Nexus spec
+	gracePeriod = 10
+	assert.Equal(t, int64(300), *podSpec.TerminationGracePeriodSeconds)
+	assert.Nil(t, podSpec.Containers[0].Lifecycle)
+
+	nexus.Spec.FreezeOnShutdown = true
+	deployment = newDeployment(nexus)
+	podSpec = deployment.Spec.Template.Spec
+	hook := podSpec.Containers[0].Lifecycle.PreStop
+	assert.NotNil(t, hook.Exec)
+	assert.Equal(t, []string{"/bin/sh", "-c", freezeScript(nexus)}, hook.Exec.Command)
+	assert.Contains(t, hook.Exec.Command[2], "http://localhost:8081/service/rest/v1/read-only/freeze")
+	assert.Contains(t, hook.Exec.Command[2], "/nexus-operator-credentials/server-user-password")
+
+	// the credentials are read from the instance Secret
+	volume := podSpec.Volumes[len(podSpec.Volumes)-1]
+	assert.Equal(t, nexus.Name, volume.Secret.SecretName)
+	assert.True(t, *volume.Secret.Optional)
+	mount := podSpec.Containers[0].VolumeMounts[len(podSpec.Containers[0].VolumeMounts)-1]
+	assert.Equal(t, volume.Name, mount.Name)
+	assert.Equal(t, operatorCredentialsMountPath, mount.MountPath)
+	assert.True(t, mount.ReadOnly)
+}
+
+func Test_freezeScript(t *testing.T) {
+	nexus := allDefaultsCommunityNexus.DeepCopy()
+	assert.Contains(t, freezeScript(nexus), "http://localhost:8081")
+	assert.NotContains(t, freezeScript(nexus), "-k")
+
+	nexus.Spec.ServerOperations.TLS.Enabled = true
+	assert.Contains(t, freezeScript(nexus), "https://localhost:8081")
+	assert.Contains(t, freezeScript(nexus), "curl -sSf -k -X POST")
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/update"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	trustedCAsInitContainerName = "nexus-truststore"
+	trustedCAsMountPath         = "/nexus-trusted-cas"
+	truststoreMountPath         = "/nexus-truststore"
+	truststoreFileName          = "cacerts.jks"
+	// the default password of the JVM truststore, which we copy the default CAs from
+	truststorePassword = "changeit"
+
+	jvmArgsTrustStore         = "-Djavax.net.ssl.trustStore"
+	jvmArgsTrustStorePassword = "-Djavax.net.ssl.trustStorePassword"
+	jvmArgsTrustStoreType     = "-Djavax.net.ssl.trustStoreType"
+)
+
+var truststoreFile = path.Join(truststoreMountPath, truststoreFileName)
+
+// truststoreScript copies the JVM default truststore and imports every certificate from the PEM bundles mounted in
+// trustedCAsMountPath. keytool only imports the first certificate of a file, so the bundles are split beforehand.
+var truststoreScript = fmt.Sprintf(`set -e
+cacerts="$(dirname "$(dirname "$(readlink -f "$(command -v keytool)")")")/lib/security/cacerts"
+if [ ! -f "$cacerts" ]; then cacerts="$(dirname "$cacerts")/../../jre/lib/security/cacerts"; fi
+cp "$cacerts" %[1]s
+chmod u+w %[1]s
+for bundle in %[2]s/*.pem; do
+  [ -f "$bundle" ] || continue
+  n=0
+  cert=""
+  while IFS= read -r line || [ -n "$line" ]; do
+    case "$line" in
+      *"BEGIN CERTIFICATE"*) cert="$line" ;;
+      *"END CERTIFICATE"*)
+        printf '%%s\n%%s\n' "$cert" "$line" > %[3]s/ca.pem
+        keytool -importcert -noprompt -keystore %[1]s -storetype JKS -storepass %[4]s -alias "$(basename "$bundle" .pem)-$n" -file %[3]s/ca.pem
+        n=$((n+1))
+        cert="" ;;
+      *) if [ -n "$cert" ]; then cert="$cert
+$line"; fi ;;
+    esac
+  done < "$bundle"
+done
+rm -f %[3]s/ca.pem`, truststoreFile, trustedCAsMountPath, truststoreMountPath, truststorePassword)
+
+// addTrustedCAs builds a truststore with the JVM default CAs and the ones from 'spec.trustedCAs' in an init container
+// and shares it with the server container. The JVM arguments pointing to it are set by buildJVMArgs.
+func addTrustedCAs(nexus *v1alpha1.Nexus, deployment *appsv1.Deployment) {
+	if len(nexus.Spec.TrustedCAs) == 0 {
+		return
+	}
+	trustedCAsVolume := fmt.Sprintf("%s-trusted-cas", nexus.Name)
+	truststoreVolume := fmt.Sprintf("%s-truststore", nexus.Name)
+	podSpec := &deployment.Spec.Template.Spec
+
+	// every bundle is projected to its own file, so there are no clashes among keys with the same name
+	var sources []corev1.VolumeProjection
+	for i, ca := range nexus.Spec.TrustedCAs {
+		fileName := fmt.Sprintf("ca-%d.pem", i)
+		if ca.SecretKeyRef != nil {
+			sources = append(sources, corev1.VolumeProjection{Secret: &corev1.SecretProjection{
+				LocalObjectReference: ca.SecretKeyRef.LocalObjectReference,
+				Items:                []corev1.KeyToPath{{Key: ca.SecretKeyRef.Key, Path: fileName}},
+				Optional:             ca.SecretKeyRef.Optional,
+			}})
+		} else if ca.ConfigMapKeyRef != nil {
+			sources = append(sources, corev1.VolumeProjection{ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: ca.ConfigMapKeyRef.LocalObjectReference,
+				Items:                []corev1.KeyToPath{{Key: ca.ConfigMapKeyRef.Key, Path: fileName}},
+				Optional:             ca.ConfigMapKeyRef.Optional,
+			}})
+		}
+	}
+	podSpec.Volumes = append(podSpec.Volumes,
+		corev1.Volume{
+			Name:         trustedCAsVolume,
+			VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: sources}},
+		},
+		corev1.Volume{
+			Name:         truststoreVolume,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts,
+		corev1.VolumeMount{Name: truststoreVolume, MountPath: truststoreMountPath, ReadOnly: true})
+
+	// the server image ships keytool and the JVM default truststore
+	podSpec.InitContainers = append(podSpec.InitContainers, corev1.Container{
+		Name:            trustedCAsInitContainerName,
+		Image:           update.MirroredImage(nexus.Spec.Image),
+		ImagePullPolicy: nexus.Spec.ImagePullPolicy,
+		Command:         []string{"sh", "-c", truststoreScript},
+		Resources:       nexus.Spec.Resources,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: trustedCAsVolume, MountPath: trustedCAsMountPath, ReadOnly: true},
+			{Name: truststoreVolume, MountPath: truststoreMountPath},
+		},
+	})
+}
+
+// trustStoreJVMArgs returns the JVM arguments pointing to the truststore built by addTrustedCAs, if any
+func trustStoreJVMArgs(nexus *v1alpha1.Nexus) map[string]string {
+	if len(nexus.Spec.TrustedCAs) == 0 {
+		return nil
+	}
+	return map[string]string{
+		jvmArgsTrustStore:         truststoreFile,
+		jvmArgsTrustStorePassword: truststorePassword,
+		jvmArgsTrustStoreType:     "JKS",
+	}
+}
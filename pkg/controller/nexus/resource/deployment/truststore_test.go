@@ -0,0 +1,80 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/validation"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_addTrustedCAs(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec: v1alpha1.NexusSpec{
+			Replicas:       1,
+			Image:          validation.NexusCommunityImage,
+			LivenessProbe:  validation.DefaultProbe,
+			ReadinessProbe: validation.DefaultProbe,
+			InitContainers: []corev1.Container{{Name: "fix-permissions", Image: "busybox"}},
+		},
+	}
+
+	// nothing to trust
+	deployment := newDeployment(nexus)
+	assert.Len(t, deployment.Spec.Template.Spec.InitContainers, 1)
+	assert.NotContains(t, deployment.Spec.Template.Spec.Containers[0].Env[0].Value, jvmArgsTrustStore)
+
+	nexus.Spec.TrustedCAs = []v1alpha1.NexusCABundleSource{
+		{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "internal-ca"}, Key: "ca.crt"}},
+		{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "gitlab-ca"}, Key: "ca.crt"}},
+	}
+	deployment = newDeployment(nexus)
+	podSpec := deployment.Spec.Template.Spec
+	// must run before the ones informed by the user
+	assert.Len(t, podSpec.InitContainers, 2)
+	assert.Equal(t, trustedCAsInitContainerName, podSpec.InitContainers[0].Name)
+	assert.Equal(t, nexus.Spec.Image, podSpec.InitContainers[0].Image)
+
+	assert.Len(t, podSpec.Volumes, 2)
+	sources := podSpec.Volumes[0].Projected.Sources
+	assert.Len(t, sources, 2)
+	assert.Equal(t, "internal-ca", sources[0].ConfigMap.Name)
+	assert.Equal(t, []corev1.KeyToPath{{Key: "ca.crt", Path: "ca-0.pem"}}, sources[0].ConfigMap.Items)
+	assert.Equal(t, "gitlab-ca", sources[1].Secret.Name)
+	assert.Equal(t, []corev1.KeyToPath{{Key: "ca.crt", Path: "ca-1.pem"}}, sources[1].Secret.Items)
+	assert.NotNil(t, podSpec.Volumes[1].EmptyDir)
+	assert.Equal(t, corev1.VolumeMount{Name: podSpec.Volumes[1].Name, MountPath: truststoreMountPath, ReadOnly: true}, podSpec.Containers[0].VolumeMounts[0])
+
+	jvmArgs := podSpec.Containers[0].Env[0].Value
+	assert.Contains(t, jvmArgs, jvmArgsTrustStore+"="+truststoreFile)
+	assert.Contains(t, jvmArgs, jvmArgsTrustStorePassword+"="+truststorePassword)
+
+	// the bundles are referenced by the pod, so updating them rolls out a new one
+	assert.True(t, IsReferenced(&podSpec, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "internal-ca", Namespace: t.Name()}}))
+	assert.True(t, IsReferenced(&podSpec, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "gitlab-ca", Namespace: t.Name()}}))
+}
+
+func Test_truststoreScript(t *testing.T) {
+	assert.True(t, strings.HasPrefix(truststoreScript, "set -e\n"))
+	assert.Contains(t, truststoreScript, "-keystore "+truststoreFile)
+	assert.Contains(t, truststoreScript, trustedCAsMountPath+"/*.pem")
+	assert.NotContains(t, truststoreScript, "%!")
+}
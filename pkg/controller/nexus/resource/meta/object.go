@@ -15,22 +15,112 @@
 package meta
 
 import (
+	"strings"
+
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const AppLabel = "app"
 
+// Kubernetes recommended labels, see https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/
+const (
+	NameLabel      = "app.kubernetes.io/name"
+	InstanceLabel  = "app.kubernetes.io/instance"
+	VersionLabel   = "app.kubernetes.io/version"
+	ComponentLabel = "app.kubernetes.io/component"
+	PartOfLabel    = "app.kubernetes.io/part-of"
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+
+	appName      = "nexus"
+	appComponent = "repository-manager"
+	operatorName = "nexus-operator"
+)
+
+// Keys of the Secret, named after the Nexus instance, holding the credentials of the Operator User in the Nexus server
+const (
+	// SecretKeyPassword secret key for the Operator User in the Nexus server
+	SecretKeyPassword = "server-user-password"
+	// SecretKeyUsername secret key for the Operator Password in the Nexus server
+	SecretKeyUsername = "server-user-username"
+)
+
+// DefaultObjectMeta creates the ObjectMeta shared by every resource created for the given Nexus instance
 func DefaultObjectMeta(nexus *v1alpha1.Nexus) v1.ObjectMeta {
 	return v1.ObjectMeta{
-		Namespace: nexus.Namespace,
-		Name:      nexus.Name,
-		Labels:    GenerateLabels(nexus),
+		Namespace:   nexus.Namespace,
+		Name:        nexus.Name,
+		Labels:      withSelectorLabels(nexus, nexus.Spec.Labels),
+		Annotations: copyMap(nexus.Spec.Annotations),
 	}
 }
 
+// DefaultPodObjectMeta creates the ObjectMeta for the Nexus pods
+func DefaultPodObjectMeta(nexus *v1alpha1.Nexus) v1.ObjectMeta {
+	return v1.ObjectMeta{
+		Namespace:   nexus.Namespace,
+		Name:        nexus.Name,
+		Labels:      withSelectorLabels(nexus, nexus.Spec.PodLabels),
+		Annotations: copyMap(nexus.Spec.PodAnnotations),
+	}
+}
+
+// GenerateLabels creates the labels used to select the resources belonging to the given Nexus instance.
+// These must never change, otherwise the pods already deployed would be orphaned.
 func GenerateLabels(nexus *v1alpha1.Nexus) map[string]string {
 	nexusAppLabels := map[string]string{}
 	nexusAppLabels[AppLabel] = nexus.Name
 	return nexusAppLabels
 }
+
+// GenerateRecommendedLabels creates the Kubernetes recommended labels for the given Nexus instance
+func GenerateRecommendedLabels(nexus *v1alpha1.Nexus) map[string]string {
+	labels := map[string]string{
+		NameLabel:      appName,
+		InstanceLabel:  nexus.Name,
+		ComponentLabel: appComponent,
+		PartOfLabel:    appName,
+		ManagedByLabel: operatorName,
+	}
+	if version := imageTag(nexus.Spec.Image); len(version) > 0 && len(validation.IsValidLabelValue(version)) == 0 {
+		labels[VersionLabel] = version
+	}
+	return labels
+}
+
+// withSelectorLabels merges the recommended labels, the given ones and the selector ones, which take precedence
+func withSelectorLabels(nexus *v1alpha1.Nexus, labels map[string]string) map[string]string {
+	merged := GenerateRecommendedLabels(nexus)
+	for key, value := range labels {
+		merged[key] = value
+	}
+	for key, value := range GenerateLabels(nexus) {
+		merged[key] = value
+	}
+	return merged
+}
+
+// imageTag extracts the tag from the given image, if any (e.g. "3.25.1" from "docker.io/sonatype/nexus3:3.25.1")
+func imageTag(image string) string {
+	if strings.Contains(image, "@") {
+		// pinned by digest
+		return ""
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"testing"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_imageTag(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"docker.io/sonatype/nexus3:3.25.1", "3.25.1"},
+		{"sonatype/nexus3", ""},
+		{"registry.corp:5000/sonatype/nexus3", ""},
+		{"registry.corp:5000/sonatype/nexus3:3.25.1", "3.25.1"},
+		{"docker.io/sonatype/nexus3@sha256:f3d8a1b2", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, imageTag(tt.image), tt.image)
+	}
+}
+
+func TestDefaultObjectMeta_recommendedLabels(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: "test"},
+		Spec: v1alpha1.NexusSpec{
+			Image:  "docker.io/sonatype/nexus3:3.25.1",
+			Labels: map[string]string{PartOfLabel: "ci-platform", AppLabel: "other"},
+		},
+	}
+	labels := DefaultObjectMeta(nexus).Labels
+
+	assert.Equal(t, map[string]string{
+		AppLabel:       "nexus3",
+		NameLabel:      "nexus",
+		InstanceLabel:  "nexus3",
+		VersionLabel:   "3.25.1",
+		ComponentLabel: "repository-manager",
+		// users are allowed to override the recommended labels, but not the selector ones
+		PartOfLabel:    "ci-platform",
+		ManagedByLabel: "nexus-operator",
+	}, labels)
+	assert.Equal(t, map[string]string{AppLabel: "nexus3"}, GenerateLabels(nexus))
+
+	nexus.Spec.Image = "docker.io/sonatype/nexus3"
+	assert.NotContains(t, DefaultObjectMeta(nexus).Labels, VersionLabel)
+}
@@ -54,6 +54,24 @@ func newIngressBuilder(nexus *v1alpha1.Nexus) *ingressBuilder {
 		},
 	}
 
+	// each extra port is served at its own host, Docker clients don't support registries under a path
+	for _, port := range exposedPorts(nexus) {
+		ingress.Spec.Rules = append(ingress.Spec.Rules, v1beta1.IngressRule{
+			Host: port.Host,
+			IngressRuleValue: v1beta1.IngressRuleValue{HTTP: &v1beta1.HTTPIngressRuleValue{
+				Paths: []v1beta1.HTTPIngressPath{
+					{
+						Path: ingressBasePath,
+						Backend: v1beta1.IngressBackend{
+							ServiceName: nexus.Name,
+							ServicePort: intstr.FromInt(int(port.Port)),
+						},
+					},
+				},
+			}},
+		})
+	}
+
 	return &ingressBuilder{Ingress: ingress, nexus: nexus}
 }
 
@@ -91,6 +91,21 @@ func TestNewIngressWithSecretName(t *testing.T) {
 	assertIngressSecretName(t, ingress)
 }
 
+func TestNewIngressWithExtraPorts(t *testing.T) {
+	nexus := ingressNexus.DeepCopy()
+	nexus.Spec.ExtraPorts = []v1alpha1.NexusPort{
+		{Name: "docker", Port: 8082, Protocol: v1.ProtocolTCP, Host: "docker.tls.test.com"},
+		// only reachable through the Service
+		{Name: "internal", Port: 8083, Protocol: v1.ProtocolTCP},
+	}
+	ingress := newIngressBuilder(nexus).withCustomTLS().build()
+
+	assert.Len(t, ingress.Spec.Rules, 2)
+	assert.Equal(t, "docker.tls.test.com", ingress.Spec.Rules[1].Host)
+	assert.Equal(t, intstr.FromInt(8082), ingress.Spec.Rules[1].HTTP.Paths[0].Backend.ServicePort)
+	assert.Equal(t, []string{nexus.Spec.Networking.Host, "docker.tls.test.com"}, ingress.Spec.TLS[0].Hosts)
+}
+
 func assertIngressBasic(t *testing.T, ingress *v1beta1.Ingress) {
 	assert.Equal(t, ingressNexus.Name, ingress.Name)
 	assert.Equal(t, ingressNexus.Namespace, ingress.Namespace)
@@ -24,6 +24,7 @@ import (
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
 	"github.com/m88i/nexus-operator/pkg/cluster/kubernetes"
 	"github.com/m88i/nexus-operator/pkg/cluster/openshift"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
 	"github.com/m88i/nexus-operator/pkg/logger"
 	routev1 "github.com/openshift/api/route/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
@@ -101,6 +102,10 @@ func (m *Manager) GetRequiredResources() ([]resource.KubernetesResource, error)
 		log.Debugf("Creating Route (%s)", m.nexus.Name)
 		route := m.createRoute()
 		resources = append(resources, route)
+		for _, port := range exposedPorts(m.nexus) {
+			log.Debugf("Creating Route (%s)", extraPortRouteName(m.nexus, port))
+			resources = append(resources, m.createExtraPortRoute(port))
+		}
 
 	case v1alpha1.IngressExposeType:
 		if !m.ingressAvailable {
@@ -122,6 +127,14 @@ func (m *Manager) createRoute() *routev1.Route {
 	return builder.build()
 }
 
+func (m *Manager) createExtraPortRoute(port v1alpha1.NexusPort) *routev1.Route {
+	builder := newExtraPortRouteBuilder(m.nexus, port)
+	if m.nexus.Spec.Networking.TLS.Mandatory {
+		builder = builder.withRedirect()
+	}
+	return builder.build()
+}
+
 func (m *Manager) createIngress() *networkingv1beta1.Ingress {
 	builder := newIngressBuilder(m.nexus)
 	if len(m.nexus.Spec.Networking.TLS.SecretName) > 0 {
@@ -140,6 +153,12 @@ func (m *Manager) GetDeployedResources() ([]resource.KubernetesResource, error)
 			log.Errorf("Could not fetch Route (%s): %v", m.nexus.Name, err)
 			return nil, fmt.Errorf("could not fetch route (%s): %v", m.nexus.Name, err)
 		}
+		extraPortRoutes, err := m.getDeployedExtraPortRoutes()
+		if err != nil {
+			log.Errorf("Could not list the extra port Routes (%s): %v", m.nexus.Name, err)
+			return nil, fmt.Errorf("could not list the extra port routes (%s): %v", m.nexus.Name, err)
+		}
+		resources = append(resources, extraPortRoutes...)
 	}
 	if m.ingressAvailable {
 		if ingress, err := m.getDeployedIngress(); err == nil {
@@ -165,6 +184,19 @@ func (m *Manager) getDeployedRoute() (*routev1.Route, error) {
 	return route, nil
 }
 
+// getDeployedExtraPortRoutes lists the Routes exposing the extra ports, which share the instance labels and are labeled with their port
+func (m *Manager) getDeployedExtraPortRoutes() ([]resource.KubernetesResource, error) {
+	routes := &routev1.RouteList{}
+	if err := m.client.List(ctx.TODO(), routes, client.InNamespace(m.nexus.Namespace), client.MatchingLabels(meta.GenerateLabels(m.nexus)), client.HasLabels{extraPortLabel}); err != nil {
+		return nil, err
+	}
+	var resources []resource.KubernetesResource
+	for i := range routes.Items {
+		resources = append(resources, &routes.Items[i])
+	}
+	return resources, nil
+}
+
 func (m *Manager) getDeployedIngress() (*networkingv1beta1.Ingress, error) {
 	ingress := &networkingv1beta1.Ingress{}
 	key := types.NamespacedName{Namespace: m.nexus.Namespace, Name: m.nexus.Name}
@@ -202,6 +234,8 @@ func ingressEqual(deployed resource.KubernetesResource, requested resource.Kuber
 	var pairs [][2]interface{}
 	pairs = append(pairs, [2]interface{}{ingress1.Name, ingress2.Name})
 	pairs = append(pairs, [2]interface{}{ingress1.Namespace, ingress2.Namespace})
+	pairs = append(pairs, [2]interface{}{ingress1.Labels, ingress2.Labels})
+	pairs = append(pairs, [2]interface{}{ingress1.Annotations, ingress2.Annotations})
 	pairs = append(pairs, [2]interface{}{ingress1.Spec, ingress2.Spec})
 
 	equal := compare.EqualPairs(pairs)
@@ -210,3 +244,14 @@ func ingressEqual(deployed resource.KubernetesResource, requested resource.Kuber
 	}
 	return equal
 }
+
+// exposedPorts returns the extra ports exposed at their own host
+func exposedPorts(nexus *v1alpha1.Nexus) []v1alpha1.NexusPort {
+	var ports []v1alpha1.NexusPort
+	for _, port := range nexus.Spec.ExtraPorts {
+		if len(port.Host) > 0 {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
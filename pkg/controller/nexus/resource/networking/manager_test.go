@@ -22,9 +22,11 @@ import (
 
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/deployment"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
 	"github.com/m88i/nexus-operator/pkg/test"
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -236,6 +238,38 @@ func TestManager_GetDeployedResources(t *testing.T) {
 	assert.Contains(t, err.Error(), mockErrorMsg)
 }
 
+func TestManager_extraPortRoutes(t *testing.T) {
+	nexus := routeNexus.DeepCopy()
+	nexus.Spec.ExtraPorts = []v1alpha1.NexusPort{
+		{Name: "docker", Port: 8082, Protocol: corev1.ProtocolTCP, Host: "docker.route.test.com"},
+		// only reachable through the Service
+		{Name: "internal", Port: 8083, Protocol: corev1.ProtocolTCP},
+	}
+	mgr := &Manager{
+		nexus:          nexus,
+		client:         test.NewFakeClientBuilder().OnOpenshift().Build(),
+		routeAvailable: true,
+		ocp:            true,
+	}
+
+	resources, err := mgr.GetRequiredResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 2)
+	extraRoute := resources[1].(*routev1.Route)
+	assert.Equal(t, "nexus3-docker", extraRoute.Name)
+	// follows the main Route TLS configuration
+	assert.NotNil(t, extraRoute.Spec.TLS)
+
+	// the extra Routes are found by the instance and extra port labels
+	assert.NoError(t, mgr.client.Create(ctx.TODO(), extraRoute))
+	unrelated := &routev1.Route{ObjectMeta: metav1.ObjectMeta{Name: "nexus3-unrelated", Namespace: nexus.Namespace, Labels: meta.GenerateLabels(nexus)}}
+	assert.NoError(t, mgr.client.Create(ctx.TODO(), unrelated))
+	resources, err = mgr.GetDeployedResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 1)
+	assert.Equal(t, "nexus3-docker", resources[0].GetName())
+}
+
 func TestManager_getDeployedRoute(t *testing.T) {
 	mgr := &Manager{
 		nexus:  routeNexus,
@@ -365,6 +399,24 @@ func TestIngressEqual(t *testing.T) {
 			}(),
 			false,
 		},
+		{
+			"All equal except labels",
+			func() *networkingv1beta1.Ingress {
+				ingress := baseIngress.DeepCopy()
+				ingress.Labels = map[string]string{"team": "platform"}
+				return ingress
+			}(),
+			false,
+		},
+		{
+			"All equal except annotations",
+			func() *networkingv1beta1.Ingress {
+				ingress := baseIngress.DeepCopy()
+				ingress.Annotations = map[string]string{"nginx.ingress.kubernetes.io/proxy-body-size": "0"}
+				return ingress
+			}(),
+			false,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -15,6 +15,8 @@
 package networking
 
 import (
+	"fmt"
+
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/deployment"
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
@@ -23,6 +25,9 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// extraPortLabel identifies the Routes exposing the extra ports, its value is the port name
+const extraPortLabel = "apps.m88i.io/extra-port"
+
 var serviceKind = (&corev1.Service{}).GroupVersionKind().Kind
 
 type routeBuilder struct {
@@ -45,6 +50,20 @@ func newRouteBuilder(nexus *v1alpha1.Nexus) *routeBuilder {
 	return &routeBuilder{route}
 }
 
+// newExtraPortRouteBuilder creates the builder for the Route exposing one of the extra ports at its own host
+func newExtraPortRouteBuilder(nexus *v1alpha1.Nexus, port v1alpha1.NexusPort) *routeBuilder {
+	builder := newRouteBuilder(nexus)
+	builder.Name = extraPortRouteName(nexus, port)
+	builder.Labels[extraPortLabel] = port.Name
+	builder.Spec.Host = port.Host
+	builder.Spec.Port.TargetPort = intstr.FromInt(int(port.Port))
+	return builder
+}
+
+func extraPortRouteName(nexus *v1alpha1.Nexus, port v1alpha1.NexusPort) string {
+	return fmt.Sprintf("%s-%s", nexus.Name, port.Name)
+}
+
 func (r *routeBuilder) withRedirect() *routeBuilder {
 	r.Spec.TLS = &v1.TLSConfig{
 		Termination:                   v1.TLSTerminationEdge,
@@ -69,10 +69,23 @@ func TestNewRouteWithRedirection(t *testing.T) {
 	assertRouteRedirection(t, route)
 }
 
+func TestNewExtraPortRoute(t *testing.T) {
+	port := v1alpha1.NexusPort{Name: "docker", Port: 8082, Protocol: corev1.ProtocolTCP, Host: "docker.route.test.com"}
+	route := newExtraPortRouteBuilder(routeNexus, port).build()
+
+	assert.Equal(t, "nexus3-docker", route.Name)
+	assert.Equal(t, routeNexus.Namespace, route.Namespace)
+	assert.Equal(t, routeNexus.Name, route.Labels[meta.AppLabel])
+	assert.Equal(t, "docker", route.Labels[extraPortLabel])
+	assert.Equal(t, port.Host, route.Spec.Host)
+	assert.Equal(t, routeService.Name, route.Spec.To.Name)
+	assert.Equal(t, intstr.FromInt(8082), route.Spec.Port.TargetPort)
+}
+
 func assertRouteBasic(t *testing.T, route *v1.Route) {
 	assert.Equal(t, routeNexus.Name, route.Name)
 	assert.Equal(t, routeNexus.Namespace, route.Namespace)
-	assert.Len(t, route.Labels, 1)
+	assert.Equal(t, "nexus-operator", route.Labels[meta.ManagedByLabel])
 	assert.Equal(t, ingressNexus.Name, route.Labels[meta.AppLabel])
 
 	assert.NotNil(t, route.Spec)
@@ -18,8 +18,10 @@ import (
 	ctx "context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/RHsyseng/operator-utils/pkg/resource"
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
 	"github.com/m88i/nexus-operator/pkg/logger"
 	corev1 "k8s.io/api/core/v1"
@@ -30,6 +32,9 @@ import (
 
 var log = logger.GetLogger("persistence_manager")
 
+// systemAnnotationPrefixes are the prefixes of the annotations set on claims by Kubernetes when binding and provisioning them
+var systemAnnotationPrefixes = []string{"pv.kubernetes.io/", "volume.kubernetes.io/", "volume.beta.kubernetes.io/"}
+
 // Manager is responsible for creating persistence resources, fetching deployed ones and comparing them
 // Use with zero values will result in a panic. Use the NewManager function to get a properly initialized manager
 type Manager struct {
@@ -49,17 +54,44 @@ func NewManager(nexus *v1alpha1.Nexus, client client.Client) *Manager {
 // GetRequiredResources returns the resources initialized by the manager
 func (m *Manager) GetRequiredResources() ([]resource.KubernetesResource, error) {
 	var resources []resource.KubernetesResource
-	if m.nexus.Spec.Persistence.Persistent {
+	// StatefulSets create their own claims from a template
+	if m.nexus.Spec.Persistence.Persistent && m.nexus.Spec.WorkloadType != v1alpha1.StatefulSetWorkloadType {
 		log.Debugf("Creating Persistent Volume Claim (%s)", m.nexus.Name)
 		pvc := newPVC(m.nexus)
+		if deployed, err := m.getDeployedPVC(); err == nil {
+			keepClaimState(deployed.(*corev1.PersistentVolumeClaim), pvc)
+		} else if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("could not fetch pvc (%s): %v", m.nexus.Name, err)
+		}
 		resources = append(resources, pvc)
 	}
 	return resources, nil
 }
 
+// keepClaimState copies what Kubernetes manages in the deployed claim to the requested one, so updating its metadata
+// doesn't touch the immutable spec, the protection finalizer or the binding annotations
+func keepClaimState(deployed, requested *corev1.PersistentVolumeClaim) {
+	requested.Spec = deployed.Spec
+	requested.Finalizers = deployed.Finalizers
+	for key, value := range deployed.Annotations {
+		for _, prefix := range systemAnnotationPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				if requested.Annotations == nil {
+					requested.Annotations = map[string]string{}
+				}
+				requested.Annotations[key] = value
+			}
+		}
+	}
+}
+
 // GetDeployedResources returns the persistence resources deployed on the cluster
 func (m *Manager) GetDeployedResources() ([]resource.KubernetesResource, error) {
 	var resources []resource.KubernetesResource
+	if m.nexus.Spec.WorkloadType == v1alpha1.StatefulSetWorkloadType {
+		// a claim left behind by a previous Deployment holds the server data, so it's kept in case the workload type is changed back
+		return resources, nil
+	}
 	if pvc, err := m.getDeployedPVC(); err == nil {
 		resources = append(resources, pvc)
 	} else if !errors.IsNotFound(err) {
@@ -86,13 +118,28 @@ func (m *Manager) getDeployedPVC() (resource.KubernetesResource, error) {
 // GetCustomComparator returns the custom comp function used to compare a persistence resource.
 // Returns nil if there is none
 func (m *Manager) GetCustomComparator(t reflect.Type) func(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
-	// As PVCs have a default comparator we just return nil here
+	if t == reflect.TypeOf(&corev1.PersistentVolumeClaim{}) {
+		return pvcEqual
+	}
 	return nil
 }
 
 // GetCustomComparators returns all custom comp functions in a map indexed by the resource type
 // Returns nil if there are none
 func (m *Manager) GetCustomComparators() map[reflect.Type]func(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
-	// As PVCs have a default comparator we just return nil here
-	return nil
+	return map[reflect.Type]func(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool{
+		reflect.TypeOf(corev1.PersistentVolumeClaim{}): pvcEqual,
+	}
+}
+
+// pvcEqual only compares the claim metadata, the spec can't be changed after the claim is created
+func pvcEqual(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
+	depPVC := deployed.(*corev1.PersistentVolumeClaim)
+	reqPVC := requested.(*corev1.PersistentVolumeClaim)
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{depPVC.Name, reqPVC.Name})
+	pairs = append(pairs, [2]interface{}{depPVC.Namespace, reqPVC.Namespace})
+	pairs = append(pairs, [2]interface{}{depPVC.Labels, reqPVC.Labels})
+	pairs = append(pairs, [2]interface{}{depPVC.Annotations, reqPVC.Annotations})
+	return compare.EqualPairs(pairs)
 }
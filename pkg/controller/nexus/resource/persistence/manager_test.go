@@ -68,6 +68,12 @@ func TestManager_GetRequiredResources(t *testing.T) {
 	// there should be a PVC with persistence
 	assert.Len(t, resources, 1)
 	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&corev1.PersistentVolumeClaim{})))
+
+	// StatefulSets create their own claims
+	mgr.nexus.Spec.WorkloadType = v1alpha1.StatefulSetWorkloadType
+	resources, err = mgr.GetRequiredResources()
+	assert.Nil(t, err)
+	assert.Len(t, resources, 0)
 }
 
 func TestManager_GetDeployedResources(t *testing.T) {
@@ -91,6 +97,13 @@ func TestManager_GetDeployedResources(t *testing.T) {
 	assert.Len(t, resources, 1)
 	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&corev1.PersistentVolumeClaim{})))
 
+	// the claim is not reported when running as a StatefulSet, so it's not removed
+	statefulNexus := mgr.nexus.DeepCopy()
+	statefulNexus.Spec.WorkloadType = v1alpha1.StatefulSetWorkloadType
+	resources, err = (&Manager{nexus: statefulNexus, client: fakeClient}).GetDeployedResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 0)
+
 	// make the client return a mocked 500 response to test errors other than NotFound
 	mockErrorMsg := "mock 500"
 	fakeClient.SetMockErrorForOneRequest(errors.NewInternalError(fmt.Errorf(mockErrorMsg)))
@@ -118,14 +131,43 @@ func TestManager_getDeployedPVC(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestManager_GetRequiredResources_deployedClaim(t *testing.T) {
+	nexus := baseNexus.DeepCopy()
+	nexus.Spec.Persistence = v1alpha1.NexusPersistence{Persistent: true, VolumeSize: "10Gi"}
+	nexus.Spec.Labels = map[string]string{"team": "platform"}
+	storageClass := "standard"
+	deployed := newPVC(&v1alpha1.Nexus{ObjectMeta: baseNexus.ObjectMeta, Spec: v1alpha1.NexusSpec{Persistence: nexus.Spec.Persistence}})
+	deployed.Spec.VolumeName = "pvc-1234"
+	deployed.Spec.StorageClassName = &storageClass
+	deployed.Finalizers = []string{"kubernetes.io/pvc-protection"}
+	deployed.Annotations = map[string]string{"pv.kubernetes.io/bind-completed": "yes"}
+	mgr := &Manager{nexus: nexus, client: test.NewFakeClientBuilder(deployed).Build()}
+
+	resources, err := mgr.GetRequiredResources()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 1)
+	required := resources[0].(*corev1.PersistentVolumeClaim)
+	assert.Equal(t, "platform", required.Labels["team"])
+	// what's managed by Kubernetes is kept
+	assert.Equal(t, deployed.Spec, required.Spec)
+	assert.Equal(t, deployed.Finalizers, required.Finalizers)
+	assert.Equal(t, "yes", required.Annotations["pv.kubernetes.io/bind-completed"])
+
+	// the new label must trigger an update
+	assert.False(t, pvcEqual(deployed, required))
+	deployed.Labels = required.Labels
+	deployed.Annotations = required.Annotations
+	assert.True(t, pvcEqual(deployed, required))
+}
+
 func TestManager_GetCustomComparator(t *testing.T) {
 	// the nexus and the client should have no effect on the
 	// comparator functions offered by the manager
 	mgr := &Manager{}
 
-	// there is no custom comparator function for PVCs
 	pvcComp := mgr.GetCustomComparator(reflect.TypeOf(&corev1.PersistentVolumeClaim{}))
-	assert.Nil(t, pvcComp)
+	assert.NotNil(t, pvcComp)
+	assert.Nil(t, mgr.GetCustomComparator(reflect.TypeOf(&corev1.Service{})))
 }
 
 func TestManager_GetCustomComparators(t *testing.T) {
@@ -133,7 +175,7 @@ func TestManager_GetCustomComparators(t *testing.T) {
 	// comparator functions offered by the manager
 	mgr := &Manager{}
 
-	// there is no custom comparator function for PVCs
 	comparators := mgr.GetCustomComparators()
-	assert.Nil(t, comparators)
+	assert.Len(t, comparators, 1)
+	assert.NotNil(t, comparators[reflect.TypeOf(corev1.PersistentVolumeClaim{})])
 }
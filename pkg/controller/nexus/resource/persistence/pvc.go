@@ -21,8 +21,19 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
+// NewClaimTemplate creates the template of the claims holding the server data for each of the StatefulSet pods.
+// Every pod has its own claim, so the access mode is always "ReadWriteOnce".
+func NewClaimTemplate(nexus *v1alpha1.Nexus, name string) corev1.PersistentVolumeClaim {
+	claim := newPVC(nexus)
+	claim.Name = name
+	claim.Namespace = ""
+	claim.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	return *claim
+}
+
 func newPVC(nexus *v1alpha1.Nexus) *corev1.PersistentVolumeClaim {
 	accessMode := corev1.ReadWriteOnce
+	// the nodes of a Pro cluster share the volume, validation rejects several replicas otherwise
 	if nexus.Spec.Replicas > 1 {
 		accessMode = corev1.ReadWriteMany
 	}
@@ -69,3 +69,29 @@ func Test_newPVC_moreThanOneReplica(t *testing.T) {
 	assert.Equal(t, corev1.ReadWriteMany, pvc.Spec.AccessModes[0])
 	assert.Equal(t, resource.MustParse(volumeSize), pvc.Spec.Resources.Requests["storage"])
 }
+
+func TestNewClaimTemplate(t *testing.T) {
+	storageClass := "fast"
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "nexus3",
+			Namespace: t.Name(),
+		},
+		Spec: v1alpha1.NexusSpec{
+			Replicas: 2,
+			Persistence: v1alpha1.NexusPersistence{
+				Persistent:   true,
+				VolumeSize:   validation.DefaultVolumeSize,
+				StorageClass: storageClass,
+			},
+		},
+	}
+	claim := NewClaimTemplate(nexus, "nexus3-data")
+
+	assert.Equal(t, "nexus3-data", claim.Name)
+	assert.Empty(t, claim.Namespace)
+	// every pod has its own claim
+	assert.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, claim.Spec.AccessModes)
+	assert.Equal(t, resource.MustParse(validation.DefaultVolumeSize), claim.Spec.Resources.Requests["storage"])
+	assert.Equal(t, &storageClass, claim.Spec.StorageClassName)
+}
@@ -19,10 +19,12 @@ import (
 	"reflect"
 
 	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/availability"
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/deployment"
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/networking"
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/persistence"
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/security"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/statefulset"
 
 	"k8s.io/client-go/discovery"
 
@@ -59,7 +61,9 @@ func (r *supervisor) InitManagers(nexus *v1alpha1.Nexus) error {
 	r.managers = []Manager{
 		deployment.NewManager(nexus, r.client),
 		persistence.NewManager(nexus, r.client),
+		statefulset.NewManager(nexus, r.client),
 		security.NewManager(nexus, r.client),
+		availability.NewManager(nexus, r.client),
 		networkManager,
 	}
 	return nil
@@ -17,8 +17,10 @@ package security
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/RHsyseng/operator-utils/pkg/resource"
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
 	"github.com/m88i/nexus-operator/pkg/framework"
 	core "k8s.io/api/core/v1"
@@ -70,6 +72,9 @@ func (m *Manager) GetCustomComparator(t reflect.Type) func(deployed resource.Kub
 	if t == reflect.TypeOf(&core.Secret{}) {
 		return framework.AlwaysTrueComparator()
 	}
+	if t == reflect.TypeOf(&core.ServiceAccount{}) {
+		return serviceAccountEqual
+	}
 	return nil
 }
 
@@ -77,6 +82,30 @@ func (m *Manager) GetCustomComparator(t reflect.Type) func(deployed resource.Kub
 // Returns nil if there are none
 func (m *Manager) GetCustomComparators() map[reflect.Type]func(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
 	return map[reflect.Type]func(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool{
-		reflect.TypeOf(core.Secret{}): framework.AlwaysTrueComparator(),
+		reflect.TypeOf(core.Secret{}):         framework.AlwaysTrueComparator(),
+		reflect.TypeOf(core.ServiceAccount{}): serviceAccountEqual,
+	}
+}
+
+func serviceAccountEqual(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
+	depAccount := deployed.(*core.ServiceAccount)
+	reqAccount := requested.(*core.ServiceAccount)
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{depAccount.Name, reqAccount.Name})
+	pairs = append(pairs, [2]interface{}{depAccount.Namespace, reqAccount.Namespace})
+	pairs = append(pairs, [2]interface{}{depAccount.Labels, reqAccount.Labels})
+	pairs = append(pairs, [2]interface{}{depAccount.Annotations, reqAccount.Annotations})
+	pairs = append(pairs, [2]interface{}{userImagePullSecrets(depAccount), reqAccount.ImagePullSecrets})
+	return compare.EqualPairs(pairs)
+}
+
+// userImagePullSecrets returns the Service Account image pull secrets without the ones added by OpenShift to pull from its internal registry
+func userImagePullSecrets(account *core.ServiceAccount) []core.LocalObjectReference {
+	var secrets []core.LocalObjectReference
+	for _, secret := range account.ImagePullSecrets {
+		if !strings.HasPrefix(secret.Name, fmt.Sprintf("%s-dockercfg-", account.Name)) {
+			secrets = append(secrets, secret)
+		}
 	}
+	return secrets
 }
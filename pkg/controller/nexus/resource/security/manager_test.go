@@ -115,11 +115,11 @@ func TestManager_GetCustomComparator(t *testing.T) {
 	// comparator functions offered by the manager
 	mgr := &Manager{}
 
-	// there is no custom comparator function for Service Accounts
-	pvcComp := mgr.GetCustomComparator(reflect.TypeOf(&corev1.ServiceAccount{}))
-	assert.Nil(t, pvcComp)
+	// there is a custom comparator function for Service Accounts
+	svcAccntComp := mgr.GetCustomComparator(reflect.TypeOf(&corev1.ServiceAccount{}))
+	assert.NotNil(t, svcAccntComp)
 
-	// there is no custom comparator function for Service Accounts
+	// there is a custom comparator function for Secrets
 	secretComp := mgr.GetCustomComparator(reflect.TypeOf(&corev1.Secret{}))
 	assert.NotNil(t, secretComp)
 }
@@ -129,7 +129,26 @@ func TestManager_GetCustomComparators(t *testing.T) {
 	// comparator functions offered by the manager
 	mgr := &Manager{}
 
-	// there is no custom comparator function for Service Accounts
+	// there are custom comparator functions for Service Accounts and Secrets
 	comparators := mgr.GetCustomComparators()
-	assert.Len(t, comparators, 1)
+	assert.Len(t, comparators, 2)
+}
+
+func Test_serviceAccountEqual(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec:       v1alpha1.NexusSpec{ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-credentials"}}},
+	}
+	requested := defaultServiceAccount(nexus)
+	assert.Equal(t, nexus.Spec.ImagePullSecrets, requested.ImagePullSecrets)
+
+	deployed := requested.DeepCopy()
+	assert.True(t, serviceAccountEqual(deployed, requested))
+
+	// added by OpenShift
+	deployed.ImagePullSecrets = append(deployed.ImagePullSecrets, corev1.LocalObjectReference{Name: "nexus3-dockercfg-x7k2p"})
+	assert.True(t, serviceAccountEqual(deployed, requested))
+
+	deployed.ImagePullSecrets = nil
+	assert.False(t, serviceAccountEqual(deployed, requested))
 }
@@ -22,7 +22,8 @@ import (
 
 func defaultServiceAccount(nexus *v1alpha1.Nexus) *corev1.ServiceAccount {
 	account := &corev1.ServiceAccount{
-		ObjectMeta: meta.DefaultObjectMeta(nexus),
+		ObjectMeta:       meta.DefaultObjectMeta(nexus),
+		ImagePullSecrets: nexus.Spec.ImagePullSecrets,
 	}
 	return account
 }
@@ -0,0 +1,157 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/RHsyseng/operator-utils/pkg/resource"
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/deployment"
+	"github.com/m88i/nexus-operator/pkg/framework"
+	"github.com/m88i/nexus-operator/pkg/logger"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var log = logger.GetLogger("statefulset_manager")
+
+// Manager is responsible for creating the StatefulSet running the Nexus pods, fetching the deployed one and comparing them
+// Use with zero values will result in a panic. Use the NewManager function to get a properly initialized manager
+type Manager struct {
+	nexus  *v1alpha1.Nexus
+	client client.Client
+}
+
+// NewManager creates a StatefulSet resources manager
+// It is expected that the Nexus has been previously validated.
+func NewManager(nexus *v1alpha1.Nexus, client client.Client) *Manager {
+	return &Manager{
+		nexus:  nexus,
+		client: client,
+	}
+}
+
+// GetRequiredResources returns the resources initialized by the manager
+func (m *Manager) GetRequiredResources() ([]resource.KubernetesResource, error) {
+	if m.nexus.Spec.WorkloadType != v1alpha1.StatefulSetWorkloadType {
+		return nil, nil
+	}
+	template, err := deployment.NewPodTemplate(m.nexus, m.client)
+	if err != nil {
+		return nil, err
+	}
+	statefulSet := newStatefulSet(m.nexus, template)
+	deployed := &appsv1.StatefulSet{}
+	if err := framework.Fetch(m.client, framework.Key(m.nexus), deployed); err == nil {
+		keepImmutableFields(deployed, statefulSet)
+	} else if !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("could not fetch Resource StatefulSet (%s): %v", m.nexus.Name, err)
+	}
+	return []resource.KubernetesResource{statefulSet, newHeadlessService(m.nexus)}, nil
+}
+
+// keepImmutableFields carries the fields which can't be changed once the StatefulSet is created over to the requested one,
+// otherwise updating it would be rejected. A warning is logged if they differ, since they'll only be applied by recreating it.
+func keepImmutableFields(deployed, requested *appsv1.StatefulSet) {
+	if !claimTemplatesEqual(deployed.Spec.VolumeClaimTemplates, requested.Spec.VolumeClaimTemplates) {
+		log.Warnf("The volume claim templates of StatefulSet %s can't be changed, 'spec.persistence' changes won't be applied. Recreate the StatefulSet to apply them", requested.Name)
+	}
+	if deployed.Spec.ServiceName != requested.Spec.ServiceName {
+		log.Warnf("StatefulSet %s is governed by Service %s instead of %s. Recreate the StatefulSet to change it", requested.Name, deployed.Spec.ServiceName, requested.Spec.ServiceName)
+	}
+	requested.Spec.VolumeClaimTemplates = deployed.Spec.VolumeClaimTemplates
+	requested.Spec.ServiceName = deployed.Spec.ServiceName
+}
+
+// GetDeployedResources returns the StatefulSet and its headless Service deployed on the cluster.
+// They're always fetched, so they're removed if the workload type is changed to Deployment.
+func (m *Manager) GetDeployedResources() ([]resource.KubernetesResource, error) {
+	var resources []resource.KubernetesResource
+	statefulSet := &appsv1.StatefulSet{}
+	if err := framework.Fetch(m.client, framework.Key(m.nexus), statefulSet); err == nil {
+		resources = append(resources, statefulSet)
+	} else if !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("could not fetch Resource StatefulSet (%s): %v", m.nexus.Name, err)
+	}
+	service := &corev1.Service{}
+	key := types.NamespacedName{Namespace: m.nexus.Namespace, Name: headlessServiceName(m.nexus)}
+	if err := framework.Fetch(m.client, key, service); err == nil {
+		resources = append(resources, service)
+	} else if !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("could not fetch Resource Service (%s): %v", key.Name, err)
+	}
+	return resources, nil
+}
+
+// GetCustomComparator returns the custom comp function used to compare a StatefulSet
+// Returns nil if there is none
+func (m *Manager) GetCustomComparator(t reflect.Type) func(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
+	if t == reflect.TypeOf(&appsv1.StatefulSet{}) {
+		return statefulSetEqual
+	}
+	return nil
+}
+
+// GetCustomComparators returns all custom comp functions in a map indexed by the resource type
+// Returns nil if there are none
+func (m *Manager) GetCustomComparators() map[reflect.Type]func(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
+	statefulSetType := reflect.TypeOf(appsv1.StatefulSet{})
+	return map[reflect.Type]func(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool{
+		statefulSetType: statefulSetEqual,
+	}
+}
+
+// statefulSetEqual ignores the volume claim templates, which can't be changed once the StatefulSet is created.
+// The deployed ones are kept by the requested StatefulSet anyway, see keepImmutableFields.
+func statefulSetEqual(deployed resource.KubernetesResource, requested resource.KubernetesResource) bool {
+	depStatefulSet := deployed.(*appsv1.StatefulSet)
+	reqStatefulSet := requested.(*appsv1.StatefulSet)
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{depStatefulSet.Name, reqStatefulSet.Name})
+	pairs = append(pairs, [2]interface{}{depStatefulSet.Namespace, reqStatefulSet.Namespace})
+	pairs = append(pairs, [2]interface{}{depStatefulSet.Labels, reqStatefulSet.Labels})
+	pairs = append(pairs, [2]interface{}{depStatefulSet.Annotations, reqStatefulSet.Annotations})
+	pairs = append(pairs, [2]interface{}{depStatefulSet.Spec.Replicas, reqStatefulSet.Spec.Replicas})
+	pairs = append(pairs, [2]interface{}{depStatefulSet.Spec.Selector, reqStatefulSet.Spec.Selector})
+	pairs = append(pairs, [2]interface{}{depStatefulSet.Spec.ServiceName, reqStatefulSet.Spec.ServiceName})
+
+	equal := compare.EqualPairs(pairs)
+	equal = equal && deployment.PodTemplateEqual(&depStatefulSet.Spec.Template, &reqStatefulSet.Spec.Template)
+	return equal
+}
+
+// claimTemplatesEqual compares the requested storage and class of the given volume claim templates
+func claimTemplatesEqual(deployed, requested []corev1.PersistentVolumeClaim) bool {
+	if len(deployed) != len(requested) {
+		return false
+	}
+	for i := range requested {
+		depStorage := deployed[i].Spec.Resources.Requests[corev1.ResourceStorage]
+		reqStorage := requested[i].Spec.Resources.Requests[corev1.ResourceStorage]
+		if depStorage.Cmp(reqStorage) != 0 {
+			return false
+		}
+		if requested[i].Spec.StorageClassName != nil && !reflect.DeepEqual(deployed[i].Spec.StorageClassName, requested[i].Spec.StorageClassName) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,226 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	ctx "context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/persistence"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/validation"
+	"github.com/m88i/nexus-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var statefulNexus = &v1alpha1.Nexus{
+	ObjectMeta: metav1.ObjectMeta{Name: "nexus-test", Namespace: "test"},
+	Spec: v1alpha1.NexusSpec{
+		Replicas:           1,
+		AutomaticUpdate:    v1alpha1.NexusAutomaticUpdate{Disabled: true},
+		ServiceAccountName: "nexus-test",
+		Resources:          validation.DefaultResources,
+		Image:              validation.NexusCommunityImage,
+		LivenessProbe:      validation.DefaultProbe.DeepCopy(),
+		ReadinessProbe:     validation.DefaultProbe.DeepCopy(),
+		StartupProbe:       validation.DefaultStartupProbe.DeepCopy(),
+		Persistence:        v1alpha1.NexusPersistence{Persistent: true, VolumeSize: validation.DefaultVolumeSize},
+		WorkloadType:       v1alpha1.StatefulSetWorkloadType,
+	},
+}
+
+func TestNewManager(t *testing.T) {
+	// default-setting logic is tested elsewhere
+	// so here we just check if the resulting manager took in the arguments correctly
+	nexus := statefulNexus
+	client := test.NewFakeClientBuilder().Build()
+	want := &Manager{
+		nexus:  nexus,
+		client: client,
+	}
+	got := NewManager(nexus, client)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("TestNewManager()\nWant: %+v\tGot: %+v", want, got)
+	}
+}
+
+func TestManager_GetRequiredResources(t *testing.T) {
+	mgr := &Manager{
+		nexus:  statefulNexus,
+		client: test.NewFakeClientBuilder().Build(),
+	}
+	resources, err := mgr.GetRequiredResources()
+	assert.Nil(t, err)
+	assert.Len(t, resources, 2)
+	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&appsv1.StatefulSet{})))
+	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&corev1.Service{})))
+
+	// the immutable fields of a deployed StatefulSet are kept
+	deployed := resources[0].(*appsv1.StatefulSet).DeepCopy()
+	deployed.Spec.ServiceName = statefulNexus.Name
+	assert.NoError(t, mgr.client.Create(ctx.TODO(), deployed))
+	mgr.nexus = statefulNexus.DeepCopy()
+	mgr.nexus.Spec.Persistence.VolumeSize = "20Gi"
+	resources, err = mgr.GetRequiredResources()
+	assert.Nil(t, err)
+	statefulSet := resources[0].(*appsv1.StatefulSet)
+	assert.Equal(t, statefulNexus.Name, statefulSet.Spec.ServiceName)
+	assert.Equal(t, deployed.Spec.VolumeClaimTemplates, statefulSet.Spec.VolumeClaimTemplates)
+
+	// nothing to create when running as a Deployment
+	mgr.nexus = statefulNexus.DeepCopy()
+	mgr.nexus.Spec.WorkloadType = v1alpha1.DeploymentWorkloadType
+	resources, err = mgr.GetRequiredResources()
+	assert.Nil(t, err)
+	assert.Len(t, resources, 0)
+}
+
+func TestManager_GetDeployedResources(t *testing.T) {
+	// first no deployed resources
+	fakeClient := test.NewFakeClientBuilder().Build()
+	mgr := &Manager{
+		nexus:  statefulNexus,
+		client: fakeClient,
+	}
+	resources, err := mgr.GetDeployedResources()
+	assert.Nil(t, resources)
+	assert.NoError(t, err)
+
+	// now with a deployed StatefulSet and its Service, which are reported regardless of the workload type, so they can be removed
+	statefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: mgr.nexus.Name, Namespace: mgr.nexus.Namespace}}
+	assert.NoError(t, mgr.client.Create(ctx.TODO(), statefulSet))
+	assert.NoError(t, mgr.client.Create(ctx.TODO(), newHeadlessService(mgr.nexus)))
+	mgr.nexus = statefulNexus.DeepCopy()
+	mgr.nexus.Spec.WorkloadType = v1alpha1.DeploymentWorkloadType
+	resources, err = mgr.GetDeployedResources()
+	assert.Nil(t, err)
+	assert.Len(t, resources, 2)
+	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&appsv1.StatefulSet{})))
+	assert.True(t, test.ContainsType(resources, reflect.TypeOf(&corev1.Service{})))
+
+	// make the client return a mocked 500 response to test errors other than NotFound
+	mockErrorMsg := "mock 500"
+	fakeClient.SetMockErrorForOneRequest(errors.NewInternalError(fmt.Errorf(mockErrorMsg)))
+	resources, err = mgr.GetDeployedResources()
+	assert.Nil(t, resources)
+	assert.Contains(t, err.Error(), mockErrorMsg)
+}
+
+func TestManager_GetCustomComparator(t *testing.T) {
+	// the nexus and the client should have no effect on the
+	// comparator functions offered by the manager
+	mgr := &Manager{}
+	assert.NotNil(t, mgr.GetCustomComparator(reflect.TypeOf(&appsv1.StatefulSet{})))
+	assert.Nil(t, mgr.GetCustomComparator(reflect.TypeOf(&corev1.Service{})))
+}
+
+func TestManager_GetCustomComparators(t *testing.T) {
+	// the nexus and the client should have no effect on the
+	// comparator functions offered by the manager
+	mgr := &Manager{}
+	comparators := mgr.GetCustomComparators()
+	assert.Len(t, comparators, 1)
+	assert.NotNil(t, comparators[reflect.TypeOf(appsv1.StatefulSet{})])
+}
+
+func Test_statefulSetEqual(t *testing.T) {
+	template, err := (&Manager{nexus: statefulNexus, client: test.NewFakeClientBuilder().Build()}).GetRequiredResources()
+	assert.NoError(t, err)
+	base := template[0].(*appsv1.StatefulSet)
+	// the cluster sets the pull policy we leave empty
+	deployed := base.DeepCopy()
+	deployed.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullAlways
+
+	tests := []struct {
+		name      string
+		req       *appsv1.StatefulSet
+		dep       *appsv1.StatefulSet
+		wantEqual bool
+	}{
+		{
+			"Equal StatefulSets",
+			base.DeepCopy(),
+			deployed.DeepCopy(),
+			true,
+		},
+		{
+			"Different replicas",
+			func() *appsv1.StatefulSet {
+				s := base.DeepCopy()
+				replicas := int32(2)
+				s.Spec.Replicas = &replicas
+				return s
+			}(),
+			deployed.DeepCopy(),
+			false,
+		},
+		{
+			"Different image",
+			func() *appsv1.StatefulSet {
+				s := base.DeepCopy()
+				s.Spec.Template.Spec.Containers[0].Image = validation.NexusCommunityImage + ":3.25.0"
+				return s
+			}(),
+			deployed.DeepCopy(),
+			false,
+		},
+		{
+			"Different volume claim templates are ignored",
+			func() *appsv1.StatefulSet {
+				s := base.DeepCopy()
+				s.Spec.VolumeClaimTemplates = nil
+				return s
+			}(),
+			deployed.DeepCopy(),
+			true,
+		},
+		{
+			"Update strategy defaulted by the cluster",
+			base.DeepCopy(),
+			func() *appsv1.StatefulSet {
+				s := deployed.DeepCopy()
+				s.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType}
+				s.Spec.PodManagementPolicy = appsv1.OrderedReadyPodManagement
+				return s
+			}(),
+			true,
+		},
+	}
+	for _, tt := range tests {
+		if got := statefulSetEqual(tt.dep, tt.req); got != tt.wantEqual {
+			t.Errorf("%s\nWant: %v\tGot: %v", tt.name, tt.wantEqual, got)
+		}
+	}
+}
+
+func Test_claimTemplatesEqual(t *testing.T) {
+	nexus := statefulNexus.DeepCopy()
+	base := []corev1.PersistentVolumeClaim{persistence.NewClaimTemplate(nexus, "data")}
+	assert.True(t, claimTemplatesEqual(base, base))
+	assert.False(t, claimTemplatesEqual(base, nil))
+
+	nexus.Spec.Persistence.VolumeSize = "20Gi"
+	assert.False(t, claimTemplatesEqual(base, []corev1.PersistentVolumeClaim{persistence.NewClaimTemplate(nexus, "data")}))
+
+	nexus = statefulNexus.DeepCopy()
+	nexus.Spec.Persistence.StorageClass = "fast"
+	assert.False(t, claimTemplatesEqual(base, []corev1.PersistentVolumeClaim{persistence.NewClaimTemplate(nexus, "data")}))
+}
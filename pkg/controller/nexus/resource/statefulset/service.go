@@ -0,0 +1,52 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"fmt"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/deployment"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// newHeadlessService creates the Service governing the StatefulSet, which gives each pod a stable DNS name.
+// Pods are published before they're ready, so the nodes of a cluster can find each other while starting.
+func newHeadlessService(nexus *v1alpha1.Nexus) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: meta.DefaultObjectMeta(nexus),
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       deployment.NexusPortName,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       deployment.NexusServicePort,
+					TargetPort: intstr.FromInt(deployment.NexusServicePort),
+				},
+			},
+			Selector:                 meta.GenerateLabels(nexus),
+			PublishNotReadyAddresses: true,
+		},
+	}
+	svc.Name = headlessServiceName(nexus)
+	return svc
+}
+
+func headlessServiceName(nexus *v1alpha1.Nexus) string {
+	return fmt.Sprintf("%s-headless", nexus.Name)
+}
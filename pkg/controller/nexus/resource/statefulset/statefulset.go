@@ -0,0 +1,54 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/deployment"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/persistence"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newStatefulSet creates a StatefulSet running the given pod template. The data volume is claimed from a template for each pod
+// instead of referencing the PVC created for Deployments.
+func newStatefulSet(nexus *v1alpha1.Nexus, template corev1.PodTemplateSpec) *appsv1.StatefulSet {
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: meta.DefaultObjectMeta(nexus),
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: deployment.Replicas(nexus),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: meta.GenerateLabels(nexus),
+			},
+			Template:    template,
+			ServiceName: headlessServiceName(nexus),
+		},
+	}
+
+	if nexus.Spec.Persistence.Persistent {
+		dataVolume := deployment.DataVolumeName(nexus)
+		var volumes []corev1.Volume
+		for _, volume := range template.Spec.Volumes {
+			if volume.Name != dataVolume {
+				volumes = append(volumes, volume)
+			}
+		}
+		statefulSet.Spec.Template.Spec.Volumes = volumes
+		statefulSet.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{persistence.NewClaimTemplate(nexus, dataVolume)}
+	}
+	return statefulSet
+}
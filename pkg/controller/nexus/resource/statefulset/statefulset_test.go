@@ -0,0 +1,47 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"testing"
+
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/deployment"
+	"github.com/m88i/nexus-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_newStatefulSet(t *testing.T) {
+	nexus := statefulNexus.DeepCopy()
+	nexus.Spec.Volumes = []corev1.Volume{{Name: "plugins", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+	template, err := deployment.NewPodTemplate(nexus, test.NewFakeClientBuilder().Build())
+	assert.NoError(t, err)
+	statefulSet := newStatefulSet(nexus, template)
+
+	assert.Equal(t, nexus.Name, statefulSet.Name)
+	assert.Equal(t, nexus.Namespace, statefulSet.Namespace)
+	assert.Equal(t, nexus.Spec.Replicas, *statefulSet.Spec.Replicas)
+	assert.Equal(t, "nexus-test-headless", statefulSet.Spec.ServiceName)
+	assert.Equal(t, statefulSet.Spec.Selector.MatchLabels, map[string]string{"app": nexus.Name})
+
+	// the data volume is claimed from the template, the other ones are kept
+	dataVolume := deployment.DataVolumeName(nexus)
+	assert.Len(t, statefulSet.Spec.VolumeClaimTemplates, 1)
+	assert.Equal(t, dataVolume, statefulSet.Spec.VolumeClaimTemplates[0].Name)
+	assert.Equal(t, nexus.Spec.Volumes, statefulSet.Spec.Template.Spec.Volumes)
+	assert.Equal(t, dataVolume, statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts[0].Name)
+	// the template we got must not be changed
+	assert.Equal(t, dataVolume, template.Spec.Volumes[0].Name)
+}
@@ -19,6 +19,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	k8sres "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -26,15 +27,33 @@ const (
 	NexusCertifiedImage = "registry.connect.redhat.com/sonatype/nexus-repository-manager"
 
 	DefaultVolumeSize = "10Gi"
+	// DefaultHeapPercentage is the share of the container memory limit given to the JVM heap
+	DefaultHeapPercentage = int32(80)
 
-	probeDefaultInitialDelaySeconds = int32(240)
+	nexusDataDir = "/nexus-data"
+
+	probeDefaultInitialDelaySeconds = int32(0)
 	probeDefaultTimeoutSeconds      = int32(15)
 	probeDefaultPeriodSeconds       = int32(10)
 	probeDefaultSuccessThreshold    = int32(1)
 	probeDefaultFailureThreshold    = int32(3)
+	// gives the server up to 10 minutes to start, liveness and readiness probes only kick in afterwards
+	startupProbeDefaultFailureThreshold = int32(60)
+
+	serverOperationsDefaultResyncPeriodSeconds = int32(600)
+
+	// OrientDB may get corrupted if the server is killed before flushing its databases
+	terminationGracePeriodDefaultSeconds = int64(120)
 )
 
 var (
+	// the user running the community image, which owns the data directory
+	nexusUID = int64(200)
+
+	disruptionBudgetDefaultMinAvailable = intstr.FromInt(1)
+
+	defaultTerminationGracePeriodSeconds = terminationGracePeriodDefaultSeconds
+
 	DefaultResources = corev1.ResourceRequirements{
 		Limits: corev1.ResourceList{
 			corev1.ResourceCPU:    k8sres.MustParse("2"),
@@ -54,6 +73,14 @@ var (
 		FailureThreshold:    probeDefaultFailureThreshold,
 	}
 
+	DefaultStartupProbe = &v1alpha1.NexusProbe{
+		InitialDelaySeconds: probeDefaultInitialDelaySeconds,
+		TimeoutSeconds:      probeDefaultTimeoutSeconds,
+		PeriodSeconds:       probeDefaultPeriodSeconds,
+		SuccessThreshold:    probeDefaultSuccessThreshold,
+		FailureThreshold:    startupProbeDefaultFailureThreshold,
+	}
+
 	DefaultPersistence = v1alpha1.NexusPersistence{
 		Persistent:   false,
 		VolumeSize:   DefaultVolumeSize,
@@ -70,6 +97,18 @@ var (
 		SecretName: "",
 	}
 
+	DefaultServerOperations = v1alpha1.ServerOperationsOpts{
+		ResyncPeriodSeconds: serverOperationsDefaultResyncPeriodSeconds,
+	}
+
+	DefaultJVM = v1alpha1.NexusJVM{
+		HeapPercentage: DefaultHeapPercentage,
+	}
+
+	DefaultDisruptionBudget = v1alpha1.NexusDisruptionBudget{
+		MinAvailable: &disruptionBudgetDefaultMinAvailable,
+	}
+
 	DefaultUpdate = v1alpha1.NexusAutomaticUpdate{
 		// this isn't really the default, but we need this off for most tests anyway
 		Disabled: true,
@@ -78,18 +117,24 @@ var (
 	AllDefaultsCommunityNexus = v1alpha1.Nexus{
 		ObjectMeta: metav1.ObjectMeta{Name: "default-community-nexus", Namespace: "default"},
 		Spec: v1alpha1.NexusSpec{
-			Replicas:                    0,
-			Image:                       NexusCommunityImage,
-			ImagePullPolicy:             "",
-			AutomaticUpdate:             DefaultUpdate,
-			Resources:                   DefaultResources,
-			Persistence:                 DefaultPersistence,
-			UseRedHatImage:              false,
-			GenerateRandomAdminPassword: false,
-			Networking:                  DefaultNetworking,
-			ServiceAccountName:          "default-community-nexus",
-			LivenessProbe:               DefaultProbe.DeepCopy(),
-			ReadinessProbe:              DefaultProbe.DeepCopy(),
+			Replicas:                      0,
+			Image:                         NexusCommunityImage,
+			ImagePullPolicy:               "",
+			AutomaticUpdate:               DefaultUpdate,
+			Resources:                     DefaultResources,
+			Persistence:                   DefaultPersistence,
+			UseRedHatImage:                false,
+			GenerateRandomAdminPassword:   false,
+			Networking:                    DefaultNetworking,
+			ServiceAccountName:            "default-community-nexus",
+			LivenessProbe:                 DefaultProbe.DeepCopy(),
+			ReadinessProbe:                DefaultProbe.DeepCopy(),
+			StartupProbe:                  DefaultStartupProbe.DeepCopy(),
+			ServerOperations:              DefaultServerOperations,
+			JVM:                           DefaultJVM,
+			WorkloadType:                  v1alpha1.DeploymentWorkloadType,
+			DisruptionBudget:              *DefaultDisruptionBudget.DeepCopy(),
+			TerminationGracePeriodSeconds: &defaultTerminationGracePeriodSeconds,
 		},
 	}
 )
@@ -21,7 +21,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const changedNexusReason = "NexusSpecChanged"
+const (
+	changedNexusReason        = "NexusSpecChanged"
+	invalidNexusReason        = "NexusSpecInvalid"
+	unsupportedReplicasReason = "UnsupportedReplicas"
+	dataDirPermissionsReason  = "DataDirPermissions"
+)
 
 func createChangedNexusEvent(nexus *v1alpha1.Nexus, scheme *runtime.Scheme, c client.Client, field string) {
 	err := kubernetes.RaiseWarnEventf(nexus, scheme, c, changedNexusReason, "'%s' has been changed in %s. Check the logs for more information", field, nexus.Name)
@@ -29,3 +34,24 @@ func createChangedNexusEvent(nexus *v1alpha1.Nexus, scheme *runtime.Scheme, c cl
 		log.Warnf("Unable to raise event for changing '%s' in Nexus (%s): %v", field, nexus.Name, err)
 	}
 }
+
+func createInvalidNexusEvent(nexus *v1alpha1.Nexus, scheme *runtime.Scheme, c client.Client, field, problem string) {
+	err := kubernetes.RaiseWarnEventf(nexus, scheme, c, invalidNexusReason, "'%s' is invalid in %s: %s", field, nexus.Name, problem)
+	if err != nil {
+		log.Warnf("Unable to raise event for invalid '%s' in Nexus (%s): %v", field, nexus.Name, err)
+	}
+}
+
+func createUnsupportedReplicasEvent(nexus *v1alpha1.Nexus, scheme *runtime.Scheme, c client.Client) {
+	err := kubernetes.RaiseWarnEventf(nexus, scheme, c, unsupportedReplicasReason, "%s can't run %d replicas: Nexus OSS nodes can't share the same data directory without corrupting it. Set 'spec.highAvailability.mode' if running Nexus Repository Pro", nexus.Name, nexus.Spec.Replicas)
+	if err != nil {
+		log.Warnf("Unable to raise event for unsupported replicas in Nexus (%s): %v", nexus.Name, err)
+	}
+}
+
+func createDataDirPermissionsEvent(nexus *v1alpha1.Nexus, scheme *runtime.Scheme, c client.Client, warning string) {
+	err := kubernetes.RaiseWarnEventf(nexus, scheme, c, dataDirPermissionsReason, "%s", warning)
+	if err != nil {
+		log.Warnf("Unable to raise event for the data directory permissions in Nexus (%s): %v", nexus.Name, err)
+	}
+}
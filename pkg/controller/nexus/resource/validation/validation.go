@@ -23,9 +23,16 @@ import (
 	"github.com/m88i/nexus-operator/pkg/cluster/kubernetes"
 	"github.com/m88i/nexus-operator/pkg/cluster/openshift"
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/update"
+	"github.com/m88i/nexus-operator/pkg/framework"
 	"github.com/m88i/nexus-operator/pkg/logger"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/discovery"
+	"strconv"
 	"strings"
 )
 
@@ -33,6 +40,11 @@ const (
 	discOCPFailureFormat      = "unable to determine if cluster is Openshift: %v"
 	discFailureFormat         = "unable to determine if %s are available: %v" // resource type, error
 	unspecifiedExposeAsFormat = "'spec.exposeAs' left unspecified, setting it to %s"
+
+	// the port served by Nexus, it can't be informed in 'spec.extraPorts'.
+	// The deployment package can't be imported here, its tests depend on this one.
+	nexusPortName = "http"
+	nexusPort     = 8081
 )
 
 var log = logger.GetLogger("Nexus_validation")
@@ -77,7 +89,74 @@ func (v *Validator) SetDefaultsAndValidate(nexus *v1alpha1.Nexus) (*v1alpha1.Nex
 }
 
 func (v *Validator) validate(nexus *v1alpha1.Nexus) error {
-	return v.validateNetworking(nexus)
+	if err := v.validateReplicas(nexus); err != nil {
+		return err
+	}
+	if err := v.validateWorkload(nexus); err != nil {
+		return err
+	}
+	if err := v.validateNetworking(nexus); err != nil {
+		return err
+	}
+	if err := v.validateExtraPorts(nexus); err != nil {
+		return err
+	}
+	if err := v.validateTrustedCAs(nexus); err != nil {
+		return err
+	}
+	return v.validateServerOperations(nexus)
+}
+
+func (v *Validator) validateReplicas(nexus *v1alpha1.Nexus) error {
+	if nexus.Spec.HighAvailability.Mode == v1alpha1.ClusteringHighAvailabilityMode {
+		return v.validateClustering(nexus)
+	}
+	if nexus.Spec.Replicas <= 1 {
+		return nil
+	}
+	log.Errorf("Nexus OSS can't run more than one replica against the same data directory. Set 'spec.replicas' to 1 or, if running Nexus Repository Pro, set 'spec.highAvailability.mode' to '%s'", v1alpha1.ClusteringHighAvailabilityMode)
+	createUnsupportedReplicasEvent(nexus, v.scheme, v.client)
+	return fmt.Errorf("%d replicas requested, but no high availability mode informed", nexus.Spec.Replicas)
+}
+
+// validateClustering makes sure HA-C nodes share a single data volume: a StatefulSet would give each node its own claim
+// and an ephemeral volume can't be shared at all
+func (v *Validator) validateClustering(nexus *v1alpha1.Nexus) error {
+	if !nexus.Spec.Persistence.Persistent {
+		log.Errorf("'spec.highAvailability.mode' set to '%s' requires a volume shared by every node. Set 'spec.persistence.persistent' to 'true'", v1alpha1.ClusteringHighAvailabilityMode)
+		createInvalidNexusEvent(nexus, v.scheme, v.client, "spec.persistence.persistent", fmt.Sprintf("'%s' requires persistence", v1alpha1.ClusteringHighAvailabilityMode))
+		return fmt.Errorf("%s high availability mode requested, but persistence is disabled", v1alpha1.ClusteringHighAvailabilityMode)
+	}
+	if nexus.Spec.WorkloadType == v1alpha1.StatefulSetWorkloadType {
+		log.Errorf("'spec.highAvailability.mode' set to '%s' requires a volume shared by every node, but a StatefulSet gives each pod its own. Set 'spec.workloadType' to '%s'", v1alpha1.ClusteringHighAvailabilityMode, v1alpha1.DeploymentWorkloadType)
+		createInvalidNexusEvent(nexus, v.scheme, v.client, "spec.workloadType", fmt.Sprintf("'%s' requires '%s'", v1alpha1.ClusteringHighAvailabilityMode, v1alpha1.DeploymentWorkloadType))
+		return fmt.Errorf("%s high availability mode requested, but the workload is a %s", v1alpha1.ClusteringHighAvailabilityMode, v1alpha1.StatefulSetWorkloadType)
+	}
+	return nil
+}
+
+// validateWorkload rejects switching a persistent instance from a Deployment to a StatefulSet: the pods would claim a new
+// volume from the template and start with an empty data directory, leaving the data in the Deployment's claim behind
+func (v *Validator) validateWorkload(nexus *v1alpha1.Nexus) error {
+	if nexus.Spec.WorkloadType != v1alpha1.StatefulSetWorkloadType || !nexus.Spec.Persistence.Persistent {
+		return nil
+	}
+	key := types.NamespacedName{Namespace: nexus.Namespace, Name: nexus.Name}
+	if err := framework.Fetch(v.client, key, &appsv1.StatefulSet{}); err == nil {
+		// the switch has already happened
+		return nil
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	if err := framework.Fetch(v.client, key, &corev1.PersistentVolumeClaim{}); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	log.Errorf("'spec.workloadType' set to '%s', but the data of %s is in the Persistent Volume Claim (%s) created for the '%s'. Migrate the data and delete the claim before switching or set 'spec.workloadType' back to '%s'", v1alpha1.StatefulSetWorkloadType, nexus.Name, nexus.Name, v1alpha1.DeploymentWorkloadType, v1alpha1.DeploymentWorkloadType)
+	createInvalidNexusEvent(nexus, v.scheme, v.client, "spec.workloadType", fmt.Sprintf("the data in the claim %s would be left behind by the %s", nexus.Name, v1alpha1.StatefulSetWorkloadType))
+	return fmt.Errorf("switching to a %s would leave the data in the pvc (%s) behind", v1alpha1.StatefulSetWorkloadType, nexus.Name)
 }
 
 func (v *Validator) validateNetworking(nexus *v1alpha1.Nexus) error {
@@ -119,13 +198,85 @@ func (v *Validator) validateNetworking(nexus *v1alpha1.Nexus) error {
 	return nil
 }
 
+func (v *Validator) validateExtraPorts(nexus *v1alpha1.Nexus) error {
+	names := map[string]bool{nexusPortName: true}
+	numbers := map[int32]bool{nexusPort: true}
+	for i, port := range nexus.Spec.ExtraPorts {
+		if problem := extraPortProblem(port, names, numbers); len(problem) > 0 {
+			field := fmt.Sprintf("spec.extraPorts[%d]", i)
+			log.Errorf("Invalid '%s': %s", field, problem)
+			createInvalidNexusEvent(nexus, v.scheme, v.client, field, problem)
+			return fmt.Errorf("invalid '%s': %s", field, problem)
+		}
+		names[port.Name], numbers[port.Port] = true, true
+	}
+	return nil
+}
+
+// extraPortProblem describes what's wrong with the given extra port, if anything. The names and numbers already taken must be informed.
+func extraPortProblem(port v1alpha1.NexusPort, names map[string]bool, numbers map[int32]bool) string {
+	if errs := utilvalidation.IsValidPortName(port.Name); len(errs) > 0 {
+		return fmt.Sprintf("invalid name (%s): %s", port.Name, strings.Join(errs, ", "))
+	}
+	if errs := utilvalidation.IsValidPortNum(int(port.Port)); len(errs) > 0 {
+		return fmt.Sprintf("invalid port (%d): %s", port.Port, strings.Join(errs, ", "))
+	}
+	if names[port.Name] || numbers[port.Port] {
+		return fmt.Sprintf("%s (%d) clashes with another port", port.Name, port.Port)
+	}
+	switch port.Protocol {
+	case corev1.ProtocolTCP, corev1.ProtocolUDP, corev1.ProtocolSCTP:
+	default:
+		return fmt.Sprintf("invalid protocol (%s)", port.Protocol)
+	}
+	// Ingresses and Routes only serve HTTP
+	if len(port.Host) > 0 && port.Protocol != corev1.ProtocolTCP {
+		return fmt.Sprintf("%s ports can't be exposed at a host (%s)", port.Protocol, port.Host)
+	}
+	return ""
+}
+
+func (v *Validator) validateTrustedCAs(nexus *v1alpha1.Nexus) error {
+	for i, ca := range nexus.Spec.TrustedCAs {
+		if (ca.SecretKeyRef == nil) == (ca.ConfigMapKeyRef == nil) {
+			field := fmt.Sprintf("spec.trustedCAs[%d]", i)
+			log.Errorf("'%s' requires exactly one of 'secretKeyRef' or 'configMapKeyRef'", field)
+			createInvalidNexusEvent(nexus, v.scheme, v.client, field, "exactly one of 'secretKeyRef' or 'configMapKeyRef' is required")
+			return fmt.Errorf("trusted ca %d must reference either a secret or a configmap", i)
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateServerOperations(nexus *v1alpha1.Nexus) error {
+	tls := nexus.Spec.ServerOperations.TLS
+	if tls.CABundle == nil {
+		return nil
+	}
+
+	if !tls.Enabled {
+		log.Warnf("'spec.serverOperations.tls.caBundle' is set, but 'spec.serverOperations.tls.enabled' is 'false'. The CA bundle will be ignored")
+	}
+
+	if (tls.CABundle.SecretKeyRef == nil) == (tls.CABundle.ConfigMapKeyRef == nil) {
+		log.Errorf("'spec.serverOperations.tls.caBundle' requires exactly one of 'secretKeyRef' or 'configMapKeyRef'")
+		return fmt.Errorf("ca bundle must reference either a secret or a configmap")
+	}
+
+	return nil
+}
+
 func (v *Validator) setDefaults(nexus *v1alpha1.Nexus) *v1alpha1.Nexus {
 	n := nexus.DeepCopy()
 	v.setDeploymentDefaults(n)
+	v.setMaintenanceDefaults(n)
 	v.setUpdateDefaults(n)
 	v.setNetworkingDefaults(n)
+	v.setExtraPortsDefaults(n)
 	v.setPersistenceDefaults(n)
+	v.setDisruptionBudgetDefaults(n)
 	v.setSecurityDefaults(n)
+	v.setServerOperationsDefaults(n)
 	return n
 }
 
@@ -133,6 +284,84 @@ func (v *Validator) setDeploymentDefaults(nexus *v1alpha1.Nexus) {
 	v.setResourcesDefaults(nexus)
 	v.setImageDefaults(nexus)
 	v.setProbeDefaults(nexus)
+	v.setJVMDefaults(nexus)
+	v.setWorkloadDefaults(nexus)
+	v.setShutdownDefaults(nexus)
+}
+
+func (v *Validator) setShutdownDefaults(nexus *v1alpha1.Nexus) {
+	if gracePeriod := nexus.Spec.TerminationGracePeriodSeconds; gracePeriod == nil || *gracePeriod < 0 {
+		if gracePeriod != nil {
+			log.Warnf("Invalid 'spec.terminationGracePeriodSeconds' (%d), setting it to %d", *gracePeriod, terminationGracePeriodDefaultSeconds)
+		}
+		defaultGracePeriod := terminationGracePeriodDefaultSeconds
+		nexus.Spec.TerminationGracePeriodSeconds = &defaultGracePeriod
+	}
+
+	if !nexus.Spec.FreezeOnShutdown {
+		return
+	}
+	// the hook needs the operator user credentials and the freeze is released by the server operations
+	if nexus.Spec.ServerOperations.DisableOperatorUserCreation {
+		log.Warnf("'spec.freezeOnShutdown' requires the operator user, but 'spec.serverOperations.disableOperatorUserCreation' is 'true'. Setting 'spec.freezeOnShutdown' to 'false'")
+		nexus.Spec.FreezeOnShutdown = false
+	} else if nexus.Spec.GenerateRandomAdminPassword {
+		log.Warnf("'spec.freezeOnShutdown' requires the server operations, which are skipped when 'spec.generateRandomAdminPassword' is 'true'. Setting 'spec.freezeOnShutdown' to 'false'")
+		nexus.Spec.FreezeOnShutdown = false
+	}
+}
+
+func (v *Validator) setWorkloadDefaults(nexus *v1alpha1.Nexus) {
+	switch nexus.Spec.WorkloadType {
+	case "":
+		nexus.Spec.WorkloadType = v1alpha1.DeploymentWorkloadType
+	case v1alpha1.DeploymentWorkloadType:
+	case v1alpha1.StatefulSetWorkloadType:
+		if !nexus.Spec.Persistence.Persistent {
+			log.Warnf("'spec.workloadType' is set to '%s', but 'spec.persistence.persistent' is 'false'. Setting it to '%s'", v1alpha1.StatefulSetWorkloadType, v1alpha1.DeploymentWorkloadType)
+			nexus.Spec.WorkloadType = v1alpha1.DeploymentWorkloadType
+		}
+	default:
+		log.Warnf("Invalid 'spec.workloadType' (%s), setting it to '%s'", nexus.Spec.WorkloadType, v1alpha1.DeploymentWorkloadType)
+		nexus.Spec.WorkloadType = v1alpha1.DeploymentWorkloadType
+	}
+}
+
+func (v *Validator) setDisruptionBudgetDefaults(nexus *v1alpha1.Nexus) {
+	budget := &nexus.Spec.DisruptionBudget
+	if budget.Disabled {
+		return
+	}
+	if !validIntOrPercent(budget.MinAvailable) {
+		log.Warnf("Invalid 'spec.disruptionBudget.minAvailable' (%s), ignoring it", budget.MinAvailable.String())
+		budget.MinAvailable = nil
+	}
+	if !validIntOrPercent(budget.MaxUnavailable) {
+		log.Warnf("Invalid 'spec.disruptionBudget.maxUnavailable' (%s), ignoring it", budget.MaxUnavailable.String())
+		budget.MaxUnavailable = nil
+	}
+	if budget.MinAvailable != nil && budget.MaxUnavailable != nil {
+		log.Warnf("Both 'spec.disruptionBudget.minAvailable' and 'spec.disruptionBudget.maxUnavailable' are set, ignoring 'spec.disruptionBudget.maxUnavailable'")
+		budget.MaxUnavailable = nil
+	}
+	if budget.MinAvailable == nil && budget.MaxUnavailable == nil {
+		budget.MinAvailable = DefaultDisruptionBudget.DeepCopy().MinAvailable
+	}
+}
+
+// validIntOrPercent checks if the value is either a non-negative number or a percentage, such as "50%"
+func validIntOrPercent(value *intstr.IntOrString) bool {
+	if value == nil {
+		return true
+	}
+	if value.Type == intstr.Int {
+		return value.IntVal >= 0
+	}
+	if !strings.HasSuffix(value.StrVal, "%") {
+		return false
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(value.StrVal, "%"))
+	return err == nil && percent >= 0 && percent <= 100
 }
 
 func (v *Validator) setResourcesDefaults(nexus *v1alpha1.Nexus) {
@@ -192,6 +421,53 @@ func (v *Validator) setProbeDefaults(nexus *v1alpha1.Nexus) {
 	} else {
 		nexus.Spec.ReadinessProbe = DefaultProbe.DeepCopy()
 	}
+
+	if nexus.Spec.StartupProbe != nil {
+		nexus.Spec.StartupProbe.FailureThreshold =
+			ensureMinimum(nexus.Spec.StartupProbe.FailureThreshold, 1)
+		nexus.Spec.StartupProbe.InitialDelaySeconds =
+			ensureMinimum(nexus.Spec.StartupProbe.InitialDelaySeconds, 0)
+		nexus.Spec.StartupProbe.PeriodSeconds =
+			ensureMinimum(nexus.Spec.StartupProbe.PeriodSeconds, 1)
+		nexus.Spec.StartupProbe.TimeoutSeconds =
+			ensureMinimum(nexus.Spec.StartupProbe.TimeoutSeconds, 1)
+	} else {
+		nexus.Spec.StartupProbe = DefaultStartupProbe.DeepCopy()
+	}
+
+	// SuccessThreshold for Startup Probes must be 1
+	nexus.Spec.StartupProbe.SuccessThreshold = 1
+}
+
+func (v *Validator) setJVMDefaults(nexus *v1alpha1.Nexus) {
+	jvm := &nexus.Spec.JVM
+	if jvm.HeapPercentage <= 0 || jvm.HeapPercentage > 100 {
+		if jvm.HeapPercentage != 0 {
+			log.Warnf("Invalid 'spec.jvm.heapPercentage' (%d), it must be between 1 and 100. Setting it to %d", jvm.HeapPercentage, DefaultHeapPercentage)
+		}
+		jvm.HeapPercentage = DefaultHeapPercentage
+	}
+
+	if len(jvm.GarbageCollector) > 0 &&
+		jvm.GarbageCollector != v1alpha1.G1GarbageCollector &&
+		jvm.GarbageCollector != v1alpha1.ParallelGarbageCollector &&
+		jvm.GarbageCollector != v1alpha1.SerialGarbageCollector {
+
+		log.Warnf("Invalid 'spec.jvm.garbageCollector', unsetting the value. The JVM default garbage collector will be used. Consider setting this value to '%s', '%s' or '%s'", v1alpha1.G1GarbageCollector, v1alpha1.ParallelGarbageCollector, v1alpha1.SerialGarbageCollector)
+		jvm.GarbageCollector = ""
+	}
+
+	if len(jvm.ExtraArgs) > 0 {
+		var validArgs []string
+		for _, arg := range jvm.ExtraArgs {
+			if strings.HasPrefix(arg, "-D") || strings.HasPrefix(arg, "-XX:") {
+				validArgs = append(validArgs, arg)
+			} else {
+				log.Warnf("Invalid JVM argument '%s' in 'spec.jvm.extraArgs', only '-D' and '-XX:' options are supported. Ignoring it", arg)
+			}
+		}
+		jvm.ExtraArgs = validArgs
+	}
 }
 
 // must be called only after image defaults have been set
@@ -199,17 +475,28 @@ func (v *Validator) setUpdateDefaults(nexus *v1alpha1.Nexus) {
 	if nexus.Spec.AutomaticUpdate.Disabled {
 		return
 	}
+	if nexus.Spec.Maintenance {
+		log.Debugf("Nexus instance is in maintenance mode, skipping the automatic update")
+		return
+	}
 
-	image := strings.Split(nexus.Spec.Image, ":")[0]
-	if image != NexusCommunityImage {
+	image := update.ImageRepository(nexus.Spec.Image)
+	if image != NexusCommunityImage && image != update.MirroredImage(NexusCommunityImage) {
 		log.Warnf("Automatic Updates are enabled, but 'spec.image' is not using the community image (%s). Disabling automatic updates", NexusCommunityImage)
 		nexus.Spec.AutomaticUpdate.Disabled = true
 		return
 	}
 
+	// the tags are listed from the repository the pods are pulled from, which is the mirror if there's any
+	repository := update.MirroredImage(image)
+	credentials, err := update.GetRegistryCredentials(v.client, nexus.Namespace, repository, nexus.Spec.ImagePullSecrets)
+	if err != nil {
+		log.Warnf("Unable to read the registry credentials from 'spec.imagePullSecrets': %v. Fetching the image tags anonymously", err)
+	}
+
 	if nexus.Spec.AutomaticUpdate.MinorVersion == nil {
 		log.Debugf("Automatic Updates are enabled, but no minor was informed. Fetching the most recent...")
-		minor, err := update.GetLatestMinor()
+		minor, err := update.GetLatestMinor(repository, credentials)
 		if err != nil {
 			log.Errorf("Unable to fetch the most recent minor: %v. Disabling automatic updates.", err)
 			nexus.Spec.AutomaticUpdate.Disabled = true
@@ -220,11 +507,11 @@ func (v *Validator) setUpdateDefaults(nexus *v1alpha1.Nexus) {
 	}
 
 	log.Debugf("Fetching the latest micro from minor %d", *nexus.Spec.AutomaticUpdate.MinorVersion)
-	tag, ok := update.GetLatestMicro(*nexus.Spec.AutomaticUpdate.MinorVersion)
+	tag, ok := update.GetLatestMicro(repository, *nexus.Spec.AutomaticUpdate.MinorVersion, credentials)
 	if !ok {
 		// the informed minor doesn't exist, let's try the latest minor
 		log.Warnf("Latest tag for minor version (%d) not found. Trying the latest minor instead", *nexus.Spec.AutomaticUpdate.MinorVersion)
-		minor, err := update.GetLatestMinor()
+		minor, err := update.GetLatestMinor(repository, credentials)
 		if err != nil {
 			log.Errorf("Unable to fetch the most recent minor: %v. Disabling automatic updates.", err)
 			nexus.Spec.AutomaticUpdate.Disabled = true
@@ -235,7 +522,7 @@ func (v *Validator) setUpdateDefaults(nexus *v1alpha1.Nexus) {
 		nexus.Spec.AutomaticUpdate.MinorVersion = &minor
 		// no need to check for the tag existence here,
 		// we would have gotten an error from GetLatestMinor() if it didn't
-		tag, _ = update.GetLatestMicro(minor)
+		tag, _ = update.GetLatestMicro(repository, minor, credentials)
 	}
 	newImage := fmt.Sprintf("%s:%s", image, tag)
 	log.Debugf("Replacing 'spec.image' (%s) with '%s'", nexus.Spec.Image, newImage)
@@ -264,6 +551,14 @@ func (v *Validator) setNetworkingDefaults(nexus *v1alpha1.Nexus) {
 	}
 }
 
+func (v *Validator) setExtraPortsDefaults(nexus *v1alpha1.Nexus) {
+	for i := range nexus.Spec.ExtraPorts {
+		if len(nexus.Spec.ExtraPorts[i].Protocol) == 0 {
+			nexus.Spec.ExtraPorts[i].Protocol = corev1.ProtocolTCP
+		}
+	}
+}
+
 func (v *Validator) setPersistenceDefaults(nexus *v1alpha1.Nexus) {
 	if nexus.Spec.Persistence.Persistent && len(nexus.Spec.Persistence.VolumeSize) == 0 {
 		nexus.Spec.Persistence.VolumeSize = DefaultVolumeSize
@@ -274,6 +569,52 @@ func (v *Validator) setSecurityDefaults(nexus *v1alpha1.Nexus) {
 	if len(nexus.Spec.ServiceAccountName) == 0 {
 		nexus.Spec.ServiceAccountName = nexus.Name
 	}
+	if warning := dataDirPermissionsWarning(nexus); len(warning) > 0 {
+		log.Warn(warning)
+		createDataDirPermissionsEvent(nexus, v.scheme, v.client, warning)
+	}
+}
+
+// dataDirPermissionsWarning describes why the user informed in the security context is not likely to be able to write to the data directory, if that's the case.
+// The directory in the image is owned by the user 200, while volumes are writable by the pod file system group.
+func dataDirPermissionsWarning(nexus *v1alpha1.Nexus) string {
+	var uid, fsGroup *int64
+	if nexus.Spec.SecurityContext != nil {
+		uid, fsGroup = nexus.Spec.SecurityContext.RunAsUser, nexus.Spec.SecurityContext.FSGroup
+	}
+	if nexus.Spec.ContainerSecurityContext != nil && nexus.Spec.ContainerSecurityContext.RunAsUser != nil {
+		uid = nexus.Spec.ContainerSecurityContext.RunAsUser
+	}
+	if uid == nil || *uid == 0 || *uid == nexusUID {
+		return ""
+	}
+	if fsGroup == nil && !nexus.Spec.UseRedHatImage {
+		// the Operator sets it for the community image
+		fsGroup = &nexusUID
+	}
+
+	switch {
+	case nexus.Spec.Persistence.Persistent && fsGroup == nil:
+		return fmt.Sprintf("The Nexus server runs as the user %d, but 'spec.securityContext.fsGroup' is not set. The server might not be able to write to the persistent volume in %s", *uid, nexusDataDir)
+	case !nexus.Spec.Persistence.Persistent && len(nexus.Spec.Properties) == 0:
+		// without persistence the data directory from the image is used, unless the server properties require an ephemeral volume
+		return fmt.Sprintf("The Nexus server runs as the user %d, but %s in the image is owned by the user %d. Consider enabling 'spec.persistence.persistent' or running as the user %d", *uid, nexusDataDir, nexusUID, nexusUID)
+	}
+	return ""
+}
+
+func (v *Validator) setServerOperationsDefaults(nexus *v1alpha1.Nexus) {
+	if nexus.Spec.ServerOperations.ResyncPeriodSeconds <= 0 {
+		nexus.Spec.ServerOperations.ResyncPeriodSeconds = serverOperationsDefaultResyncPeriodSeconds
+	}
+}
+
+func (v *Validator) setMaintenanceDefaults(nexus *v1alpha1.Nexus) {
+	// the server is frozen through its API, which can't be reached with a random admin password
+	if nexus.Spec.Maintenance && nexus.Spec.GenerateRandomAdminPassword {
+		log.Warnf("'spec.maintenance' requires the server operations, which are skipped when 'spec.generateRandomAdminPassword' is 'true'. Setting 'spec.maintenance' to 'false'")
+		nexus.Spec.Maintenance = false
+	}
 }
 
 func ensureMinimum(value, minimum int32) int32 {
@@ -16,6 +16,7 @@ package validation
 
 import (
 	"fmt"
+	"os"
 	"reflect"
 	"testing"
 
@@ -23,7 +24,11 @@ import (
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/update"
 	"github.com/m88i/nexus-operator/pkg/test"
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func TestNewValidator(t *testing.T) {
@@ -157,15 +162,35 @@ func TestValidator_SetDefaultsAndValidate_Deployment(t *testing.T) {
 			}(),
 		},
 		{
-			"Unset 'spec.livenessProbe' and 'spec.readinessProbe'",
+			"Unset 'spec.livenessProbe', 'spec.readinessProbe' and 'spec.startupProbe'",
 			func() *v1alpha1.Nexus {
 				nexus := AllDefaultsCommunityNexus.DeepCopy()
 				nexus.Spec.LivenessProbe = nil
 				nexus.Spec.ReadinessProbe = nil
+				nexus.Spec.StartupProbe = nil
 				return nexus
 			}(),
 			AllDefaultsCommunityNexus.DeepCopy(),
 		},
+		{
+			"'spec.startupProbe.*' don't meet minimum values",
+			func() *v1alpha1.Nexus {
+				nexus := AllDefaultsCommunityNexus.DeepCopy()
+				nexus.Spec.StartupProbe = &v1alpha1.NexusProbe{
+					InitialDelaySeconds: -1,
+					TimeoutSeconds:      -1,
+					PeriodSeconds:       -1,
+					SuccessThreshold:    3,
+					FailureThreshold:    -1,
+				}
+				return nexus
+			}(),
+			func() *v1alpha1.Nexus {
+				nexus := AllDefaultsCommunityNexus.DeepCopy()
+				nexus.Spec.StartupProbe = minimumDefaultProbe.DeepCopy()
+				return nexus
+			}(),
+		},
 		{
 			"Invalid 'spec.imagePullPolicy'",
 			func() *v1alpha1.Nexus {
@@ -194,7 +219,7 @@ func TestValidator_setUpdateDefaults(t *testing.T) {
 	nexus.Spec.Image = NexusCommunityImage
 
 	v.setUpdateDefaults(nexus)
-	latestMinor, err := update.GetLatestMinor()
+	latestMinor, err := update.GetLatestMinor(NexusCommunityImage, update.RegistryCredentials{})
 	if err != nil {
 		// If we couldn't fetch the tags updates should be disabled
 		assert.True(t, nexus.Spec.AutomaticUpdate.Disabled)
@@ -214,7 +239,7 @@ func TestValidator_setUpdateDefaults(t *testing.T) {
 	bogusMinor := -1
 	nexus.Spec.AutomaticUpdate.MinorVersion = &bogusMinor
 	v.setUpdateDefaults(nexus)
-	latestMinor, err = update.GetLatestMinor()
+	latestMinor, err = update.GetLatestMinor(NexusCommunityImage, update.RegistryCredentials{})
 	if err != nil {
 		// If we couldn't fetch the tags updates should be disabled
 		assert.True(t, nexus.Spec.AutomaticUpdate.Disabled)
@@ -222,6 +247,13 @@ func TestValidator_setUpdateDefaults(t *testing.T) {
 	} else {
 		assert.Equal(t, latestMinor, *nexus.Spec.AutomaticUpdate.MinorVersion)
 	}
+
+	// No updates while in maintenance mode
+	nexus = &v1alpha1.Nexus{Spec: v1alpha1.NexusSpec{AutomaticUpdate: v1alpha1.NexusAutomaticUpdate{}, Maintenance: true}}
+	nexus.Spec.Image = NexusCommunityImage
+	v.setUpdateDefaults(nexus)
+	assert.Nil(t, nexus.Spec.AutomaticUpdate.MinorVersion)
+	assert.Equal(t, NexusCommunityImage, nexus.Spec.Image)
 }
 
 func TestValidator_setNetworkingDefaults(t *testing.T) {
@@ -427,6 +459,125 @@ func TestValidator_validateNetworking(t *testing.T) {
 	}
 }
 
+func TestValidator_validateServerOperations(t *testing.T) {
+	secretRef := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}, Key: "ca.crt"}
+	configMapRef := &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}, Key: "ca.crt"}
+	tests := []struct {
+		name      string
+		input     v1alpha1.ServerOperationsTLS
+		wantError bool
+	}{
+		{
+			"TLS disabled",
+			v1alpha1.ServerOperationsTLS{},
+			false,
+		},
+		{
+			"TLS enabled without CA bundle",
+			v1alpha1.ServerOperationsTLS{Enabled: true},
+			false,
+		},
+		{
+			"CA bundle from a Secret",
+			v1alpha1.ServerOperationsTLS{Enabled: true, CABundle: &v1alpha1.NexusCABundleSource{SecretKeyRef: secretRef}},
+			false,
+		},
+		{
+			"CA bundle from a ConfigMap",
+			v1alpha1.ServerOperationsTLS{Enabled: true, CABundle: &v1alpha1.NexusCABundleSource{ConfigMapKeyRef: configMapRef}},
+			false,
+		},
+		{
+			"CA bundle referencing nothing",
+			v1alpha1.ServerOperationsTLS{Enabled: true, CABundle: &v1alpha1.NexusCABundleSource{}},
+			true,
+		},
+		{
+			"CA bundle referencing both a Secret and a ConfigMap",
+			v1alpha1.ServerOperationsTLS{Enabled: true, CABundle: &v1alpha1.NexusCABundleSource{SecretKeyRef: secretRef, ConfigMapKeyRef: configMapRef}},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		v := &Validator{}
+		nexus := &v1alpha1.Nexus{Spec: v1alpha1.NexusSpec{ServerOperations: v1alpha1.ServerOperationsOpts{TLS: tt.input}}}
+		if err := v.validateServerOperations(nexus); (err != nil) != tt.wantError {
+			t.Errorf("%s\nWantError: %v\tError: %v", tt.name, tt.wantError, err)
+		}
+	}
+}
+
+func TestValidator_validateReplicas(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     v1alpha1.NexusSpec
+		wantEvent string
+	}{
+		{
+			"Single replica",
+			v1alpha1.NexusSpec{Replicas: 1},
+			"",
+		},
+		{
+			"Several replicas on Nexus OSS",
+			v1alpha1.NexusSpec{Replicas: 3},
+			unsupportedReplicasReason,
+		},
+		{
+			"Several replicas on a Nexus Repository Pro cluster",
+			v1alpha1.NexusSpec{Replicas: 3, HighAvailability: v1alpha1.NexusHighAvailability{Mode: v1alpha1.ClusteringHighAvailabilityMode}, Persistence: v1alpha1.NexusPersistence{Persistent: true}, WorkloadType: v1alpha1.DeploymentWorkloadType},
+			"",
+		},
+		{
+			"Nexus Repository Pro cluster without persistence",
+			v1alpha1.NexusSpec{Replicas: 3, HighAvailability: v1alpha1.NexusHighAvailability{Mode: v1alpha1.ClusteringHighAvailabilityMode}, WorkloadType: v1alpha1.DeploymentWorkloadType},
+			invalidNexusReason,
+		},
+		{
+			"Nexus Repository Pro cluster running as a StatefulSet",
+			v1alpha1.NexusSpec{Replicas: 3, HighAvailability: v1alpha1.NexusHighAvailability{Mode: v1alpha1.ClusteringHighAvailabilityMode}, Persistence: v1alpha1.NexusPersistence{Persistent: true}, WorkloadType: v1alpha1.StatefulSetWorkloadType},
+			invalidNexusReason,
+		},
+	}
+
+	for _, tt := range tests {
+		client := test.NewFakeClientBuilder().Build()
+		v := &Validator{client: client, scheme: client.Scheme()}
+		nexus := &v1alpha1.Nexus{ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}, Spec: tt.input}
+		err := v.validateReplicas(nexus)
+		assert.Equal(t, len(tt.wantEvent) > 0, err != nil, tt.name)
+		if len(tt.wantEvent) > 0 {
+			assert.True(t, test.EventExists(client, tt.wantEvent), tt.name)
+		}
+	}
+}
+
+func TestValidator_validateWorkload(t *testing.T) {
+	persistent := v1alpha1.NexusSpec{WorkloadType: v1alpha1.StatefulSetWorkloadType, Persistence: v1alpha1.NexusPersistence{Persistent: true}}
+	objectMeta := metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}
+	tests := []struct {
+		name      string
+		spec      v1alpha1.NexusSpec
+		objects   []runtime.Object
+		wantError bool
+	}{
+		{"Deployment", v1alpha1.NexusSpec{WorkloadType: v1alpha1.DeploymentWorkloadType, Persistence: persistent.Persistence}, []runtime.Object{&corev1.PersistentVolumeClaim{ObjectMeta: objectMeta}}, false},
+		{"New StatefulSet", persistent, nil, false},
+		{"Switching from a Deployment with data", persistent, []runtime.Object{&corev1.PersistentVolumeClaim{ObjectMeta: objectMeta}}, true},
+		{"StatefulSet already deployed", persistent, []runtime.Object{&corev1.PersistentVolumeClaim{ObjectMeta: objectMeta}, &appsv1.StatefulSet{ObjectMeta: objectMeta}}, false},
+	}
+	for _, tt := range tests {
+		client := test.NewFakeClientBuilder(tt.objects...).Build()
+		v := &Validator{client: client, scheme: client.Scheme()}
+		nexus := &v1alpha1.Nexus{ObjectMeta: objectMeta, Spec: tt.spec}
+		if err := v.validateWorkload(nexus); (err != nil) != tt.wantError {
+			t.Errorf("%s\nWantError: %v\tError: %v", tt.name, tt.wantError, err)
+		}
+		assert.Equal(t, tt.wantError, test.EventExists(client, invalidNexusReason), tt.name)
+	}
+}
+
 func TestValidator_SetDefaultsAndValidate_Persistence(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -484,3 +635,416 @@ func TestValidator_SetDefaultsAndValidate_Security(t *testing.T) {
 		}
 	}
 }
+
+func TestValidator_SetDefaultsAndValidate_ServerOperations(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *v1alpha1.Nexus
+		want  *v1alpha1.Nexus
+	}{
+		{
+			"'spec.serverOperations.resyncPeriodSeconds' left blank",
+			func() *v1alpha1.Nexus {
+				nexus := AllDefaultsCommunityNexus.DeepCopy()
+				nexus.Spec.ServerOperations.ResyncPeriodSeconds = 0
+				return nexus
+			}(),
+			&AllDefaultsCommunityNexus,
+		},
+		{
+			"'spec.serverOperations.resyncPeriodSeconds' is negative",
+			func() *v1alpha1.Nexus {
+				nexus := AllDefaultsCommunityNexus.DeepCopy()
+				nexus.Spec.ServerOperations.ResyncPeriodSeconds = -1
+				return nexus
+			}(),
+			&AllDefaultsCommunityNexus,
+		},
+		{
+			"'spec.serverOperations.resyncPeriodSeconds' is set",
+			func() *v1alpha1.Nexus {
+				nexus := AllDefaultsCommunityNexus.DeepCopy()
+				nexus.Spec.ServerOperations.ResyncPeriodSeconds = 60
+				return nexus
+			}(),
+			func() *v1alpha1.Nexus {
+				nexus := AllDefaultsCommunityNexus.DeepCopy()
+				nexus.Spec.ServerOperations.ResyncPeriodSeconds = 60
+				return nexus
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		v := &Validator{}
+		got, err := v.SetDefaultsAndValidate(tt.input)
+		assert.Nil(t, err)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s\nWant: %+v\nGot: %+v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestValidator_setJVMDefaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		input v1alpha1.NexusJVM
+		want  v1alpha1.NexusJVM
+	}{
+		{
+			"'spec.jvm' left blank",
+			v1alpha1.NexusJVM{},
+			DefaultJVM,
+		},
+		{
+			"Invalid 'spec.jvm.heapPercentage'",
+			v1alpha1.NexusJVM{HeapPercentage: 120},
+			DefaultJVM,
+		},
+		{
+			"Invalid 'spec.jvm.garbageCollector'",
+			v1alpha1.NexusJVM{HeapPercentage: 50, GarbageCollector: "ConcMarkSweep"},
+			v1alpha1.NexusJVM{HeapPercentage: 50},
+		},
+		{
+			"Valid options",
+			v1alpha1.NexusJVM{HeapPercentage: 50, GarbageCollector: v1alpha1.G1GarbageCollector, MaxDirectMemorySize: "1g", ExtraArgs: []string{"-Dnexus.foo=bar", "-XX:+ExitOnOutOfMemoryError"}},
+			v1alpha1.NexusJVM{HeapPercentage: 50, GarbageCollector: v1alpha1.G1GarbageCollector, MaxDirectMemorySize: "1g", ExtraArgs: []string{"-Dnexus.foo=bar", "-XX:+ExitOnOutOfMemoryError"}},
+		},
+		{
+			"Invalid 'spec.jvm.extraArgs' are removed",
+			v1alpha1.NexusJVM{HeapPercentage: 80, ExtraArgs: []string{"-Xmx4g", "-Dnexus.foo=bar", "--add-opens"}},
+			v1alpha1.NexusJVM{HeapPercentage: 80, ExtraArgs: []string{"-Dnexus.foo=bar"}},
+		},
+	}
+	for _, tt := range tests {
+		v := &Validator{}
+		nexus := &v1alpha1.Nexus{Spec: v1alpha1.NexusSpec{JVM: tt.input}}
+		v.setJVMDefaults(nexus)
+		if !reflect.DeepEqual(nexus.Spec.JVM, tt.want) {
+			t.Errorf("%s\nWant: %+v\nGot: %+v", tt.name, tt.want, nexus.Spec.JVM)
+		}
+	}
+}
+
+func TestValidator_setImageDefaults_withMirrors(t *testing.T) {
+	_ = os.Setenv(update.ImageMirrorsEnvKey, "docker.io/sonatype/nexus3=registry.corp/sonatype/nexus3")
+	defer os.Unsetenv(update.ImageMirrorsEnvKey)
+	v := &Validator{}
+
+	// mirrors are applied to the pods, 'spec.image' is left as informed
+	nexus := &v1alpha1.Nexus{}
+	v.setImageDefaults(nexus)
+	assert.Equal(t, NexusCommunityImage, nexus.Spec.Image)
+
+	nexus = &v1alpha1.Nexus{Spec: v1alpha1.NexusSpec{Image: NexusCommunityImage + ":3.25.0"}}
+	v.setImageDefaults(nexus)
+	assert.Equal(t, NexusCommunityImage+":3.25.0", nexus.Spec.Image)
+}
+
+func TestValidator_validateTrustedCAs(t *testing.T) {
+	configMapCA := v1alpha1.NexusCABundleSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "internal-ca"}, Key: "ca.crt"}}
+	secretCA := v1alpha1.NexusCABundleSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "gitlab-ca"}, Key: "ca.crt"}}
+	tests := []struct {
+		name      string
+		input     []v1alpha1.NexusCABundleSource
+		wantError bool
+	}{
+		{
+			"'spec.trustedCAs' left blank",
+			nil,
+			false,
+		},
+		{
+			"Valid CAs",
+			[]v1alpha1.NexusCABundleSource{configMapCA, secretCA},
+			false,
+		},
+		{
+			"CA without references",
+			[]v1alpha1.NexusCABundleSource{configMapCA, {}},
+			true,
+		},
+		{
+			"CA with both references",
+			[]v1alpha1.NexusCABundleSource{{ConfigMapKeyRef: configMapCA.ConfigMapKeyRef, SecretKeyRef: secretCA.SecretKeyRef}},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		client := test.NewFakeClientBuilder().Build()
+		v := &Validator{client: client, scheme: client.Scheme()}
+		nexus := &v1alpha1.Nexus{ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}, Spec: v1alpha1.NexusSpec{TrustedCAs: tt.input}}
+		if err := v.validateTrustedCAs(nexus); (err != nil) != tt.wantError {
+			t.Errorf("%s\nWantError: %v\tError: %v", tt.name, tt.wantError, err)
+		}
+		assert.Equal(t, tt.wantError, test.EventExists(client, invalidNexusReason), tt.name)
+	}
+}
+
+func TestValidator_setWorkloadDefaults(t *testing.T) {
+	persistent := v1alpha1.NexusPersistence{Persistent: true}
+	tests := []struct {
+		name  string
+		input v1alpha1.NexusSpec
+		want  v1alpha1.NexusWorkloadType
+	}{
+		{
+			"'spec.workloadType' left blank",
+			v1alpha1.NexusSpec{},
+			v1alpha1.DeploymentWorkloadType,
+		},
+		{
+			"StatefulSet with persistence",
+			v1alpha1.NexusSpec{WorkloadType: v1alpha1.StatefulSetWorkloadType, Persistence: persistent},
+			v1alpha1.StatefulSetWorkloadType,
+		},
+		{
+			"StatefulSet without persistence",
+			v1alpha1.NexusSpec{WorkloadType: v1alpha1.StatefulSetWorkloadType},
+			v1alpha1.DeploymentWorkloadType,
+		},
+		{
+			"Invalid workload type",
+			v1alpha1.NexusSpec{WorkloadType: "DaemonSet", Persistence: persistent},
+			v1alpha1.DeploymentWorkloadType,
+		},
+	}
+	for _, tt := range tests {
+		v := &Validator{}
+		nexus := &v1alpha1.Nexus{Spec: tt.input}
+		v.setWorkloadDefaults(nexus)
+		if nexus.Spec.WorkloadType != tt.want {
+			t.Errorf("%s\nWant: %v\tGot: %v", tt.name, tt.want, nexus.Spec.WorkloadType)
+		}
+	}
+}
+
+func TestValidator_setDisruptionBudgetDefaults(t *testing.T) {
+	minAvailable := intstr.FromInt(2)
+	maxUnavailable := intstr.FromString("50%")
+	invalidPercentage := intstr.FromString("half")
+	tests := []struct {
+		name  string
+		input v1alpha1.NexusDisruptionBudget
+		want  v1alpha1.NexusDisruptionBudget
+	}{
+		{
+			"'spec.disruptionBudget' left blank",
+			v1alpha1.NexusDisruptionBudget{},
+			DefaultDisruptionBudget,
+		},
+		{
+			"Disabled budget is left as is",
+			v1alpha1.NexusDisruptionBudget{Disabled: true},
+			v1alpha1.NexusDisruptionBudget{Disabled: true},
+		},
+		{
+			"Valid minAvailable",
+			v1alpha1.NexusDisruptionBudget{MinAvailable: &minAvailable},
+			v1alpha1.NexusDisruptionBudget{MinAvailable: &minAvailable},
+		},
+		{
+			"Valid maxUnavailable",
+			v1alpha1.NexusDisruptionBudget{MaxUnavailable: &maxUnavailable},
+			v1alpha1.NexusDisruptionBudget{MaxUnavailable: &maxUnavailable},
+		},
+		{
+			"Both set, maxUnavailable is dropped",
+			v1alpha1.NexusDisruptionBudget{MinAvailable: &minAvailable, MaxUnavailable: &maxUnavailable},
+			v1alpha1.NexusDisruptionBudget{MinAvailable: &minAvailable},
+		},
+		{
+			"Invalid percentage falls back to the default",
+			v1alpha1.NexusDisruptionBudget{MaxUnavailable: &invalidPercentage},
+			DefaultDisruptionBudget,
+		},
+	}
+	for _, tt := range tests {
+		v := &Validator{}
+		nexus := &v1alpha1.Nexus{Spec: v1alpha1.NexusSpec{DisruptionBudget: tt.input}}
+		v.setDisruptionBudgetDefaults(nexus)
+		if !reflect.DeepEqual(nexus.Spec.DisruptionBudget, tt.want) {
+			t.Errorf("%s\nWant: %+v\nGot: %+v", tt.name, tt.want, nexus.Spec.DisruptionBudget)
+		}
+	}
+}
+
+func Test_validIntOrPercent(t *testing.T) {
+	tests := []struct {
+		value intstr.IntOrString
+		want  bool
+	}{
+		{intstr.FromInt(0), true},
+		{intstr.FromInt(3), true},
+		{intstr.FromInt(-1), false},
+		{intstr.FromString("25%"), true},
+		{intstr.FromString("100%"), true},
+		{intstr.FromString("101%"), false},
+		{intstr.FromString("25"), false},
+		{intstr.FromString("many%"), false},
+	}
+	for _, tt := range tests {
+		value := tt.value
+		assert.Equal(t, tt.want, validIntOrPercent(&value), value.String())
+	}
+	assert.True(t, validIntOrPercent(nil))
+}
+
+func TestValidator_setShutdownDefaults(t *testing.T) {
+	negative := int64(-1)
+	custom := int64(300)
+	tests := []struct {
+		name            string
+		input           v1alpha1.NexusSpec
+		wantGracePeriod int64
+		wantFreeze      bool
+	}{
+		{
+			"'spec.terminationGracePeriodSeconds' left blank",
+			v1alpha1.NexusSpec{},
+			terminationGracePeriodDefaultSeconds,
+			false,
+		},
+		{
+			"Negative grace period",
+			v1alpha1.NexusSpec{TerminationGracePeriodSeconds: &negative},
+			terminationGracePeriodDefaultSeconds,
+			false,
+		},
+		{
+			"Custom grace period and freeze",
+			v1alpha1.NexusSpec{TerminationGracePeriodSeconds: &custom, FreezeOnShutdown: true},
+			custom,
+			true,
+		},
+		{
+			"Freeze without the operator user",
+			v1alpha1.NexusSpec{FreezeOnShutdown: true, ServerOperations: v1alpha1.ServerOperationsOpts{DisableOperatorUserCreation: true}},
+			terminationGracePeriodDefaultSeconds,
+			false,
+		},
+		{
+			"Freeze without server operations",
+			v1alpha1.NexusSpec{FreezeOnShutdown: true, GenerateRandomAdminPassword: true},
+			terminationGracePeriodDefaultSeconds,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		v := &Validator{}
+		nexus := &v1alpha1.Nexus{Spec: tt.input}
+		v.setShutdownDefaults(nexus)
+		assert.Equal(t, tt.wantGracePeriod, *nexus.Spec.TerminationGracePeriodSeconds, tt.name)
+		assert.Equal(t, tt.wantFreeze, nexus.Spec.FreezeOnShutdown, tt.name)
+	}
+}
+
+func TestValidator_setExtraPortsDefaults(t *testing.T) {
+	v := &Validator{}
+	nexus := &v1alpha1.Nexus{Spec: v1alpha1.NexusSpec{ExtraPorts: []v1alpha1.NexusPort{
+		{Name: "docker", Port: 8082, Host: "docker.example.com"},
+		{Name: "syslog", Port: 5514, Protocol: corev1.ProtocolUDP},
+	}}}
+	v.setExtraPortsDefaults(nexus)
+	assert.Equal(t, []v1alpha1.NexusPort{
+		{Name: "docker", Port: 8082, Protocol: corev1.ProtocolTCP, Host: "docker.example.com"},
+		{Name: "syslog", Port: 5514, Protocol: corev1.ProtocolUDP},
+	}, nexus.Spec.ExtraPorts)
+}
+
+func TestValidator_validateExtraPorts(t *testing.T) {
+	docker := v1alpha1.NexusPort{Name: "docker", Port: 8082, Protocol: corev1.ProtocolTCP, Host: "docker.example.com"}
+	tests := []struct {
+		name      string
+		ports     []v1alpha1.NexusPort
+		wantError bool
+	}{
+		{"No extra ports", nil, false},
+		{"Valid ports", []v1alpha1.NexusPort{docker, {Name: "syslog", Port: 5514, Protocol: corev1.ProtocolUDP}}, false},
+		{"Clashes with the Nexus port name", []v1alpha1.NexusPort{{Name: "http", Port: 8090, Protocol: corev1.ProtocolTCP}}, true},
+		{"Clashes with the Nexus port number", []v1alpha1.NexusPort{{Name: "nexus", Port: 8081, Protocol: corev1.ProtocolTCP}}, true},
+		{"Clashes with another extra port", []v1alpha1.NexusPort{docker, {Name: "docker-group", Port: 8082, Protocol: corev1.ProtocolTCP}}, true},
+		{"Invalid name", []v1alpha1.NexusPort{{Name: "Not_A_Port_Name", Port: 8084, Protocol: corev1.ProtocolTCP}}, true},
+		{"Invalid port", []v1alpha1.NexusPort{{Name: "huge", Port: 70000, Protocol: corev1.ProtocolTCP}}, true},
+		{"Invalid protocol", []v1alpha1.NexusPort{{Name: "quic", Port: 8085, Protocol: "QUIC"}}, true},
+		{"UDP exposed at a host", []v1alpha1.NexusPort{{Name: "syslog", Port: 5514, Protocol: corev1.ProtocolUDP, Host: "syslog.example.com"}}, true},
+	}
+
+	for _, tt := range tests {
+		client := test.NewFakeClientBuilder().Build()
+		v := &Validator{client: client, scheme: client.Scheme()}
+		nexus := &v1alpha1.Nexus{ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}, Spec: v1alpha1.NexusSpec{ExtraPorts: tt.ports}}
+		if err := v.validateExtraPorts(nexus); (err != nil) != tt.wantError {
+			t.Errorf("%s\nWantError: %v\tError: %v", tt.name, tt.wantError, err)
+		}
+		assert.Equal(t, tt.wantError, test.EventExists(client, invalidNexusReason), tt.name)
+	}
+}
+
+func TestValidator_setMaintenanceDefaults(t *testing.T) {
+	v := &Validator{}
+	nexus := &v1alpha1.Nexus{Spec: v1alpha1.NexusSpec{Maintenance: true, ScaleDownOnMaintenance: true}}
+	v.setMaintenanceDefaults(nexus)
+	assert.True(t, nexus.Spec.Maintenance)
+
+	nexus.Spec.GenerateRandomAdminPassword = true
+	v.setMaintenanceDefaults(nexus)
+	assert.False(t, nexus.Spec.Maintenance)
+}
+
+func Test_dataDirPermissionsWarning(t *testing.T) {
+	root := int64(0)
+	arbitrary := int64(1000680000)
+	tests := []struct {
+		name        string
+		spec        v1alpha1.NexusSpec
+		wantWarning bool
+	}{
+		{
+			"Default user",
+			v1alpha1.NexusSpec{},
+			false,
+		},
+		{
+			"Root user",
+			v1alpha1.NexusSpec{SecurityContext: &corev1.PodSecurityContext{RunAsUser: &root}},
+			false,
+		},
+		{
+			"Arbitrary user with persistence and the default file system group",
+			v1alpha1.NexusSpec{SecurityContext: &corev1.PodSecurityContext{RunAsUser: &arbitrary}, Persistence: v1alpha1.NexusPersistence{Persistent: true}},
+			false,
+		},
+		{
+			"Arbitrary user with persistence and no file system group",
+			v1alpha1.NexusSpec{UseRedHatImage: true, SecurityContext: &corev1.PodSecurityContext{RunAsUser: &arbitrary}, Persistence: v1alpha1.NexusPersistence{Persistent: true}},
+			true,
+		},
+		{
+			"Arbitrary container user without persistence",
+			v1alpha1.NexusSpec{ContainerSecurityContext: &corev1.SecurityContext{RunAsUser: &arbitrary}},
+			true,
+		},
+		{
+			"Arbitrary user with an ephemeral data volume",
+			v1alpha1.NexusSpec{SecurityContext: &corev1.PodSecurityContext{RunAsUser: &arbitrary}, Properties: map[string]string{"nexus.scripts.allowCreation": "true"}},
+			false,
+		},
+		{
+			"Container user overrides the pod one",
+			v1alpha1.NexusSpec{SecurityContext: &corev1.PodSecurityContext{RunAsUser: &arbitrary}, ContainerSecurityContext: &corev1.SecurityContext{RunAsUser: &nexusUID}},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		nexus := &v1alpha1.Nexus{ObjectMeta: metav1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}, Spec: tt.spec}
+		warning := dataDirPermissionsWarning(nexus)
+		assert.Equal(t, tt.wantWarning, len(warning) > 0, tt.name)
+
+		// the warning is also raised as an event
+		client := test.NewFakeClientBuilder().Build()
+		v := &Validator{client: client, scheme: client.Scheme()}
+		v.setSecurityDefaults(nexus)
+		assert.Equal(t, tt.wantWarning, test.EventExists(client, dataDirPermissionsReason), tt.name)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	repositoriesPath       = "/service/rest/v1/repositories"
+	dockerRepositoryFormat = "docker"
+	dockerPortNamePrefix   = "docker-"
+)
+
+// repositorySummary is a repository as listed by the repositories API
+type repositorySummary struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Type   string `json:"type"`
+}
+
+// dockerRepository is a Docker repository as reported by the repositories API
+type dockerRepository struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Docker struct {
+		// HTTPPort is the port of the repository HTTP connector, if any
+		HTTPPort *int32 `json:"httpPort"`
+	} `json:"docker"`
+}
+
+func (s *statusClient) DockerRepositories() ([]dockerRepository, error) {
+	resp, err := s.get(repositoriesPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response from %s: %s", repositoriesPath, resp.Status)
+	}
+	var summaries []repositorySummary
+	if err := json.NewDecoder(resp.Body).Decode(&summaries); err != nil {
+		return nil, fmt.Errorf("unable to decode response from %s: %v", repositoriesPath, err)
+	}
+
+	var repositories []dockerRepository
+	for _, summary := range summaries {
+		if summary.Format != dockerRepositoryFormat {
+			continue
+		}
+		repository, err := s.dockerRepository(summary)
+		if err != nil {
+			return nil, err
+		}
+		repositories = append(repositories, repository)
+	}
+	return repositories, nil
+}
+
+func (s *statusClient) dockerRepository(summary repositorySummary) (dockerRepository, error) {
+	repository := dockerRepository{}
+	path := fmt.Sprintf("%s/%s/%s/%s", repositoriesPath, dockerRepositoryFormat, url.PathEscape(summary.Type), url.PathEscape(summary.Name))
+	resp, err := s.get(path)
+	if err != nil {
+		return repository, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return repository, fmt.Errorf("unexpected response from %s: %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&repository); err != nil {
+		return repository, fmt.Errorf("unable to decode response from %s: %v", path, err)
+	}
+	return repository, nil
+}
+
+// ensureDockerConnectorPorts looks for the Docker repositories with an HTTP connector, so their ports can be added to the Service
+func (s *server) ensureDockerConnectorPorts() error {
+	if !s.nexus.Spec.ServerOperations.ExposeDockerConnectors {
+		s.status.DockerConnectorPorts = nil
+		return nil
+	}
+	repositories, err := s.statuscli.DockerRepositories()
+	if err != nil {
+		return fmt.Errorf("unable to fetch the Docker repositories: %v", err)
+	}
+
+	var ports []v1alpha1.NexusPort
+	for _, repository := range repositories {
+		if repository.Docker.HTTPPort == nil {
+			continue
+		}
+		port := *repository.Docker.HTTPPort
+		ports = append(ports, v1alpha1.NexusPort{Name: dockerPortName(port), Port: port, Protocol: corev1.ProtocolTCP})
+	}
+	// the order must be stable, otherwise the Service would be updated every time
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Port < ports[j].Port })
+	s.status.DockerConnectorPorts = ports
+	return nil
+}
+
+// dockerPortName names the port after its number, repository names aren't valid port names
+func dockerPortName(port int32) string {
+	return fmt.Sprintf("%s%d", dockerPortNamePrefix, port)
+}
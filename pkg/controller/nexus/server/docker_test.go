@@ -0,0 +1,156 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
+	"github.com/m88i/nexus-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_statusClient_DockerRepositories(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case repositoriesPath:
+			_, _ = w.Write([]byte(`[{"name":"maven-public","format":"maven2","type":"group"},{"name":"docker-hosted","format":"docker","type":"hosted"},{"name":"docker-hub","format":"docker","type":"proxy"}]`))
+		case repositoriesPath + "/docker/hosted/docker-hosted":
+			_, _ = w.Write([]byte(`{"name":"docker-hosted","type":"hosted","docker":{"v1Enabled":false,"forceBasicAuth":true,"httpPort":8082}}`))
+		case repositoriesPath + "/docker/proxy/docker-hub":
+			_, _ = w.Write([]byte(`{"name":"docker-hub","type":"proxy","docker":{"v1Enabled":false,"forceBasicAuth":true,"httpPort":null}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	client := newStatusClient(srv.URL, defaultAdminUsername, defaultAdminPassword, http.DefaultClient)
+	repositories, err := client.DockerRepositories()
+	assert.NoError(t, err)
+	assert.Len(t, repositories, 2)
+	assert.Equal(t, "docker-hosted", repositories[0].Name)
+	assert.Equal(t, int32(8082), *repositories[0].Docker.HTTPPort)
+	assert.Nil(t, repositories[1].Docker.HTTPPort)
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == repositoriesPath {
+			_, _ = w.Write([]byte(`[{"name":"docker-hosted","format":"docker","type":"hosted"}]`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer broken.Close()
+	client = newStatusClient(broken.URL, defaultAdminUsername, defaultAdminPassword, http.DefaultClient)
+	_, err = client.DockerRepositories()
+	assert.Error(t, err)
+}
+
+func Test_server_ensureDockerConnectorPorts(t *testing.T) {
+	hostedPort, groupPort := int32(8083), int32(8082)
+	repositories := []dockerRepository{{Name: "docker-hosted"}, {Name: "docker-group"}, {Name: "docker-hub"}}
+	repositories[0].Docker.HTTPPort = &hostedPort
+	repositories[1].Docker.HTTPPort = &groupPort
+	nexus := &v1alpha1.Nexus{ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}}
+	statusAPI := &fakeStatusAPI{dockerRepos: repositories}
+	s := server{nexus: nexus, status: &v1alpha1.OperationsStatus{}, statuscli: statusAPI}
+
+	// disabled
+	assert.NoError(t, s.ensureDockerConnectorPorts())
+	assert.Empty(t, s.status.DockerConnectorPorts)
+
+	nexus.Spec.ServerOperations.ExposeDockerConnectors = true
+	assert.NoError(t, s.ensureDockerConnectorPorts())
+	assert.Equal(t, []v1alpha1.NexusPort{
+		{Name: "docker-8082", Port: 8082, Protocol: corev1.ProtocolTCP},
+		{Name: "docker-8083", Port: 8083, Protocol: corev1.ProtocolTCP},
+	}, s.status.DockerConnectorPorts)
+
+	statusAPI.dockerErr = fmt.Errorf("forbidden")
+	assert.Error(t, s.ensureDockerConnectorPorts())
+}
+
+func Test_handleServerOperationsKeepsDockerConnectorPorts(t *testing.T) {
+	ports := []v1alpha1.NexusPort{{Name: "docker-8082", Port: 8082, Protocol: corev1.ProtocolTCP}}
+	nexus := &v1alpha1.Nexus{
+		Spec:       v1alpha1.NexusSpec{ServerOperations: v1alpha1.ServerOperationsOpts{ExposeDockerConnectors: true}},
+		ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Status: v1alpha1.NexusStatus{
+			DeploymentStatus:       appv1.DeploymentStatus{AvailableReplicas: 0},
+			ServerOperationsStatus: v1alpha1.OperationsStatus{DockerConnectorPorts: ports},
+		},
+	}
+	cli := test.NewFakeClientBuilder(nexus).Build()
+	// the server can't be reached, the ports found before are kept
+	status, err := handleServerOperations(nexus, cli, cli.Scheme(), nexusAPIFakeBuilder, statusAPIFakeBuilder)
+	assert.NoError(t, err)
+	assert.False(t, status.ServerReady)
+	assert.Equal(t, ports, status.DockerConnectorPorts)
+
+	// not exposed anymore
+	nexus.Spec.ServerOperations.ExposeDockerConnectors = false
+	status, err = handleServerOperations(nexus, cli, cli.Scheme(), nexusAPIFakeBuilder, statusAPIFakeBuilder)
+	assert.NoError(t, err)
+	assert.Empty(t, status.DockerConnectorPorts)
+}
+
+func Test_handleServerOperationsUpToDateKeepsDockerConnectors(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		Spec:       v1alpha1.NexusSpec{ServerOperations: v1alpha1.ServerOperationsOpts{ResyncPeriodSeconds: 600, ExposeDockerConnectors: true}},
+		ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Status:     v1alpha1.NexusStatus{DeploymentStatus: appv1.DeploymentStatus{AvailableReplicas: 1}},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: meta.DefaultObjectMeta(nexus),
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 8081}}},
+	}
+	cli := test.NewFakeClientBuilder(nexus, svc, &corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: nexus.Name, Namespace: nexus.Namespace}}).Build()
+	port := int32(8082)
+	repositories := []dockerRepository{{Name: "docker-hosted"}}
+	repositories[0].Docker.HTTPPort = &port
+	calls := 0
+	withRepository := func(url, user, pass string, httpClient *http.Client) statusAPI {
+		calls++
+		return &fakeStatusAPI{writable: true, dockerRepos: repositories}
+	}
+	status, err := handleServerOperations(nexus, cli, cli.Scheme(), nexusAPIFakeBuilder, withRepository)
+	assert.NoError(t, err)
+	assert.True(t, status.ServerReady)
+	assert.Equal(t, []v1alpha1.NexusPort{{Name: "docker-8082", Port: 8082, Protocol: corev1.ProtocolTCP}}, status.DockerConnectorPorts)
+	assert.Equal(t, 1, calls)
+	nexus.Status.ServerOperationsStatus = status
+
+	// the server isn't reached while the configuration is up to date, the ports found before are carried forward
+	kept, err := handleServerOperations(nexus, cli, cli.Scheme(), nexusAPIFakeBuilder, withRepository)
+	assert.NoError(t, err)
+	assert.Equal(t, status, kept)
+	assert.Equal(t, 1, calls)
+
+	// once the resync is due the repositories are looked up again
+	past := v1.NewTime(time.Now().Add(-time.Hour))
+	nexus.Status.ServerOperationsStatus.LastAppliedTime = &past
+	repositories = nil
+	resynced, err := handleServerOperations(nexus, cli, cli.Scheme(), nexusAPIFakeBuilder, withRepository)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, resynced.DockerConnectorPorts)
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"github.com/m88i/nexus-operator/pkg/cluster/kubernetes"
+)
+
+const (
+	operatorUserCreatedReason           = "OperatorUserCreated"
+	operatorUserCreationFailedReason    = "OperatorUserCreationFailed"
+	repositoriesCreatedReason           = "RepositoriesCreated"
+	repositoriesCreationFailedReason    = "RepositoriesCreationFailed"
+	mavenCentralGroupUpdatedReason      = "MavenCentralGroupUpdated"
+	mavenCentralGroupUpdateFailedReason = "MavenCentralGroupUpdateFailed"
+	readOnlyReleasedReason              = "ReadOnlyReleased"
+	maintenanceStartedReason            = "MaintenanceStarted"
+	maintenanceFinishedReason           = "MaintenanceFinished"
+)
+
+func (s *server) createInfoEvent(reason, messageFormat string, args ...interface{}) {
+	if err := kubernetes.RaiseInfoEventf(s.nexus, s.scheme, s.k8sclient, reason, messageFormat, args...); err != nil {
+		log.Warnf("Unable to raise event %s for Nexus instance %s: %v", reason, s.nexus.Name, err)
+	}
+}
+
+func (s *server) createWarningEvent(reason, messageFormat string, args ...interface{}) {
+	if err := kubernetes.RaiseWarnEventf(s.nexus, s.scheme, s.k8sclient, reason, messageFormat, args...); err != nil {
+		log.Warnf("Unable to raise event %s for Nexus instance %s: %v", reason, s.nexus.Name, err)
+	}
+}
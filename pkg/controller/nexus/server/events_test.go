@@ -0,0 +1,63 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	ctx "context"
+	"fmt"
+	"testing"
+
+	"github.com/m88i/nexus-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_server_createInfoEvent(t *testing.T) {
+	server, client := createNewServerAndKubeCli(t)
+	server.createInfoEvent(repositoriesCreatedReason, "Created proxy repositories %v", []string{"apache"})
+
+	eventList := &corev1.EventList{}
+	_ = client.List(ctx.TODO(), eventList)
+	assert.Len(t, eventList.Items, 1)
+	assert.Equal(t, repositoriesCreatedReason, eventList.Items[0].Reason)
+	assert.Equal(t, corev1.EventTypeNormal, eventList.Items[0].Type)
+	assert.Equal(t, "Created proxy repositories [apache]", eventList.Items[0].Message)
+}
+
+func Test_server_createWarningEvent(t *testing.T) {
+	server, client := createNewServerAndKubeCli(t)
+	restErr := fmt.Errorf("401 Unauthorized")
+	server.createWarningEvent(operatorUserCreationFailedReason, "Failed to create operator user '%s': %v", operatorUsername, restErr)
+
+	eventList := &corev1.EventList{}
+	_ = client.List(ctx.TODO(), eventList)
+	assert.Len(t, eventList.Items, 1)
+	assert.Equal(t, operatorUserCreationFailedReason, eventList.Items[0].Reason)
+	assert.Equal(t, corev1.EventTypeWarning, eventList.Items[0].Type)
+	assert.Contains(t, eventList.Items[0].Message, restErr.Error())
+}
+
+func Test_userOperation_EnsureOperatorUser_NoEventWhenUserExists(t *testing.T) {
+	server, client := createNewServerAndKubeCli(t, &corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}})
+	// make sure the user is there already
+	user, err := userOperations(server).(*userOperation).createOperatorUserInstance()
+	assert.NoError(t, err)
+	assert.NoError(t, server.nexuscli.UserService.Add(*user))
+
+	assert.NoError(t, userOperations(server).EnsureOperatorUser())
+	assert.False(t, test.EventExists(client, operatorUserCreatedReason))
+	assert.False(t, test.EventExists(client, operatorUserCreationFailedReason))
+}
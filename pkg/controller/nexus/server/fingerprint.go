@@ -0,0 +1,75 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/m88i/aicura/nexus"
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+)
+
+// serverConfig is the desired server-side configuration, everything that changes what the server operations do must be here
+type serverConfig struct {
+	DisableRepositoryCreation   bool                                  `json:"disableRepositoryCreation"`
+	DisableOperatorUserCreation bool                                  `json:"disableOperatorUserCreation"`
+	MavenProxies                map[string]nexus.MavenProxyRepository `json:"mavenProxies"`
+	ExposeDockerConnectors      bool                                  `json:"exposeDockerConnectors"`
+	TLS                         v1alpha1.ServerOperationsTLS          `json:"tls"`
+}
+
+// configFingerprint calculates a hash of the desired server-side configuration for the given Nexus instance
+func configFingerprint(nexus *v1alpha1.Nexus) (string, error) {
+	config := serverConfig{
+		DisableRepositoryCreation:   nexus.Spec.ServerOperations.DisableRepositoryCreation,
+		DisableOperatorUserCreation: nexus.Spec.ServerOperations.DisableOperatorUserCreation,
+		MavenProxies:                communityMavenProxies,
+		ExposeDockerConnectors:      nexus.Spec.ServerOperations.ExposeDockerConnectors,
+		TLS:                         nexus.Spec.ServerOperations.TLS,
+	}
+	// maps are marshaled with sorted keys, so the output is deterministic
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(raw)), nil
+}
+
+// isUpToDate checks if the last server operations succeeded with the same configuration within the resync period
+func isUpToDate(nexus *v1alpha1.Nexus, hash string) bool {
+	last := nexus.Status.ServerOperationsStatus
+	if !last.ServerReady || len(last.Reason) > 0 || last.LastAppliedTime == nil || last.ConfigHash != hash {
+		return false
+	}
+	resyncPeriod := time.Duration(nexus.Spec.ServerOperations.ResyncPeriodSeconds) * time.Second
+	return time.Since(last.LastAppliedTime.Time) < resyncPeriod
+}
+
+// untilResync returns how long until the server operations are due again because of the resync period.
+// Returns 0 if they haven't succeeded yet.
+func untilResync(nexus *v1alpha1.Nexus) time.Duration {
+	last := nexus.Status.ServerOperationsStatus
+	resyncPeriod := time.Duration(nexus.Spec.ServerOperations.ResyncPeriodSeconds) * time.Second
+	if !last.ServerReady || last.LastAppliedTime == nil || resyncPeriod <= 0 {
+		return 0
+	}
+	if remaining := resyncPeriod - time.Since(last.LastAppliedTime.Time); remaining > 0 {
+		return remaining
+	}
+	return retryBaseInterval
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_configFingerprint(t *testing.T) {
+	nexus := &v1alpha1.Nexus{ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}}
+	hash, err := configFingerprint(nexus)
+	assert.NoError(t, err)
+	again, err := configFingerprint(nexus)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, again)
+
+	// the resync period is not part of the server configuration
+	nexus.Spec.ServerOperations.ResyncPeriodSeconds = 60
+	again, err = configFingerprint(nexus)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, again)
+
+	nexus.Spec.ServerOperations.DisableOperatorUserCreation = true
+	changed, err := configFingerprint(nexus)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, changed)
+
+	nexus.Spec.ServerOperations.ExposeDockerConnectors = true
+	again, err = configFingerprint(nexus)
+	assert.NoError(t, err)
+	assert.NotEqual(t, changed, again)
+
+	// the operator reaches the server differently, so the operations must run again
+	nexus.Spec.ServerOperations.TLS.Enabled = true
+	changed, err = configFingerprint(nexus)
+	assert.NoError(t, err)
+	assert.NotEqual(t, again, changed)
+}
+
+func Test_isUpToDate(t *testing.T) {
+	recently := v1.NewTime(time.Now().Add(-time.Minute))
+	nexus := &v1alpha1.Nexus{
+		Spec: v1alpha1.NexusSpec{ServerOperations: v1alpha1.ServerOperationsOpts{ResyncPeriodSeconds: 600}},
+		Status: v1alpha1.NexusStatus{ServerOperationsStatus: v1alpha1.OperationsStatus{
+			ServerReady:     true,
+			ConfigHash:      "hash",
+			LastAppliedTime: &recently,
+		}},
+	}
+	assert.True(t, isUpToDate(nexus, "hash"))
+	assert.False(t, isUpToDate(nexus, "another-hash"))
+
+	nexus.Spec.ServerOperations.ResyncPeriodSeconds = 30
+	assert.False(t, isUpToDate(nexus, "hash"))
+	nexus.Spec.ServerOperations.ResyncPeriodSeconds = 600
+
+	nexus.Status.ServerOperationsStatus.Reason = "failed last time"
+	assert.False(t, isUpToDate(nexus, "hash"))
+
+	nexus.Status.ServerOperationsStatus = v1alpha1.OperationsStatus{}
+	assert.False(t, isUpToDate(nexus, "hash"))
+}
+
+func Test_untilResync(t *testing.T) {
+	recently := v1.NewTime(time.Now().Add(-time.Minute))
+	nexus := &v1alpha1.Nexus{
+		Spec: v1alpha1.NexusSpec{ServerOperations: v1alpha1.ServerOperationsOpts{ResyncPeriodSeconds: 600}},
+		Status: v1alpha1.NexusStatus{ServerOperationsStatus: v1alpha1.OperationsStatus{
+			ServerReady:     true,
+			LastAppliedTime: &recently,
+		}},
+	}
+	remaining := untilResync(nexus)
+	assert.True(t, remaining > 8*time.Minute && remaining <= 9*time.Minute)
+	assert.Equal(t, remaining.Round(time.Minute), RequeueAfter(nexus).Round(time.Minute))
+
+	// already due
+	nexus.Spec.ServerOperations.ResyncPeriodSeconds = 30
+	assert.Equal(t, retryBaseInterval, untilResync(nexus))
+
+	// never succeeded
+	nexus.Status.ServerOperationsStatus = v1alpha1.OperationsStatus{}
+	assert.Equal(t, time.Duration(0), untilResync(nexus))
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HandleMaintenance freezes the server when the instance is put in maintenance and releases it once the maintenance is over.
+// Returns whether the server is frozen for maintenance, which should be kept in 'status.maintenanceFrozen'.
+func HandleMaintenance(nexus *v1alpha1.Nexus, client client.Client, scheme *runtime.Scheme) (bool, error) {
+	return handleMaintenance(nexus, client, scheme, newStatusClient)
+}
+
+func handleMaintenance(nexus *v1alpha1.Nexus, client client.Client, scheme *runtime.Scheme, statusAPIBuilder func(url, user, pass string, httpClient *http.Client) statusAPI) (bool, error) {
+	frozen := nexus.Status.MaintenanceFrozen
+	if nexus.Spec.Maintenance == frozen {
+		return frozen, nil
+	}
+	// when leaving maintenance, the instance is scaled back up before we get here
+	if nexus.Status.DeploymentStatus.AvailableReplicas == 0 {
+		log.Infof("Nexus instance %s has no available replicas, waiting for them to change its maintenance mode", nexus.Name)
+		return frozen, nil
+	}
+
+	s := server{nexus: nexus, k8sclient: client, scheme: scheme, status: &v1alpha1.OperationsStatus{}}
+	endpoint, err := s.getNexusEndpoint()
+	if err != nil {
+		return frozen, fmt.Errorf("unable to resolve endpoint for Nexus instance %s: %v", nexus.Name, err)
+	}
+	httpClient, err := s.newHTTPClient()
+	if err != nil {
+		return frozen, fmt.Errorf("unable to configure TLS for Nexus instance %s: %v", nexus.Name, err)
+	}
+	user, pass := s.getStatusCredentials()
+	s.statuscli = statusAPIBuilder(endpoint, user, pass, httpClient)
+
+	if nexus.Spec.Maintenance {
+		return s.freezeForMaintenance()
+	}
+	return s.releaseFromMaintenance()
+}
+
+func (s *server) freezeForMaintenance() (bool, error) {
+	if err := s.statuscli.Freeze(); err != nil {
+		return false, fmt.Errorf("unable to freeze Nexus instance %s for maintenance: %v", s.nexus.Name, err)
+	}
+	// make sure the freeze took effect before reporting it, the instance may be scaled down afterwards
+	state, err := s.statuscli.ReadOnlyState()
+	if err != nil {
+		return false, fmt.Errorf("unable to verify if Nexus instance %s is frozen: %v", s.nexus.Name, err)
+	}
+	if !state.Frozen {
+		return false, fmt.Errorf("nexus instance %s is not frozen yet", s.nexus.Name)
+	}
+	log.Infof("Nexus instance %s frozen for maintenance", s.nexus.Name)
+	s.createInfoEvent(maintenanceStartedReason, "Server frozen for maintenance")
+	return true, nil
+}
+
+func (s *server) releaseFromMaintenance() (bool, error) {
+	if err := s.statuscli.Release(); err != nil {
+		return true, fmt.Errorf("unable to release Nexus instance %s from maintenance: %v", s.nexus.Name, err)
+	}
+	log.Infof("Nexus instance %s released from maintenance", s.nexus.Name)
+	s.createInfoEvent(maintenanceFinishedReason, "Server released from maintenance")
+	return false, nil
+}
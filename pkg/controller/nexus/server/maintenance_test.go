@@ -0,0 +1,108 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
+	"github.com/m88i/nexus-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_handleMaintenance(t *testing.T) {
+	nexus := &v1alpha1.Nexus{ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}, Spec: v1alpha1.NexusSpec{Maintenance: true}}
+	nexus.Status.DeploymentStatus.AvailableReplicas = 1
+	svc := &corev1.Service{ObjectMeta: meta.DefaultObjectMeta(nexus), Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8081}}}}
+	cli := test.NewFakeClientBuilder(nexus, svc).Build()
+	fakeAPI := &fakeStatusAPI{writable: true}
+	builder := func(url, user, pass string, httpClient *http.Client) statusAPI { return fakeAPI }
+
+	// entering maintenance
+	frozen, err := handleMaintenance(nexus, cli, cli.Scheme(), builder)
+	assert.NoError(t, err)
+	assert.True(t, frozen)
+	assert.True(t, fakeAPI.readOnly.Frozen)
+	assert.True(t, test.EventExists(cli, maintenanceStartedReason))
+
+	// already frozen, nothing to do
+	nexus.Status.MaintenanceFrozen = true
+	fakeAPI.freezeErr = fmt.Errorf("should not be called")
+	frozen, err = handleMaintenance(nexus, cli, cli.Scheme(), builder)
+	assert.NoError(t, err)
+	assert.True(t, frozen)
+
+	// leaving maintenance
+	nexus.Spec.Maintenance = false
+	frozen, err = handleMaintenance(nexus, cli, cli.Scheme(), builder)
+	assert.NoError(t, err)
+	assert.False(t, frozen)
+	assert.True(t, fakeAPI.released)
+	assert.True(t, test.EventExists(cli, maintenanceFinishedReason))
+}
+
+func Test_handleMaintenanceWaitsForReplicas(t *testing.T) {
+	// scaled down during maintenance, the freeze can only be released once the server is back
+	nexus := &v1alpha1.Nexus{ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}}
+	nexus.Status.MaintenanceFrozen = true
+	cli := test.NewFakeClientBuilder(nexus).Build()
+	fakeAPI := &fakeStatusAPI{readOnly: readOnlyState{Frozen: true}}
+	builder := func(url, user, pass string, httpClient *http.Client) statusAPI { return fakeAPI }
+
+	frozen, err := handleMaintenance(nexus, cli, cli.Scheme(), builder)
+	assert.NoError(t, err)
+	assert.True(t, frozen)
+	assert.False(t, fakeAPI.released)
+}
+
+func Test_handleMaintenanceFailures(t *testing.T) {
+	nexus := &v1alpha1.Nexus{ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}, Spec: v1alpha1.NexusSpec{Maintenance: true}}
+	nexus.Status.DeploymentStatus.AvailableReplicas = 1
+	svc := &corev1.Service{ObjectMeta: meta.DefaultObjectMeta(nexus), Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8081}}}}
+	cli := test.NewFakeClientBuilder(nexus, svc).Build()
+	fakeAPI := &fakeStatusAPI{}
+	builder := func(url, user, pass string, httpClient *http.Client) statusAPI { return fakeAPI }
+
+	// unable to freeze
+	fakeAPI.freezeErr = fmt.Errorf("forbidden")
+	frozen, err := handleMaintenance(nexus, cli, cli.Scheme(), builder)
+	assert.Error(t, err)
+	assert.False(t, frozen)
+
+	// unable to verify the freeze
+	fakeAPI.freezeErr = nil
+	fakeAPI.readOnlyErr = fmt.Errorf("forbidden")
+	frozen, err = handleMaintenance(nexus, cli, cli.Scheme(), builder)
+	assert.Error(t, err)
+	assert.False(t, frozen)
+
+	// unable to release
+	nexus.Spec.Maintenance = false
+	nexus.Status.MaintenanceFrozen = true
+	fakeAPI.releaseErr = fmt.Errorf("forbidden")
+	frozen, err = handleMaintenance(nexus, cli, cli.Scheme(), builder)
+	assert.Error(t, err)
+	assert.True(t, frozen)
+
+	// no service to reach the server
+	cli = test.NewFakeClientBuilder(nexus).Build()
+	_, err = handleMaintenance(nexus, cli, cli.Scheme(), builder)
+	assert.Error(t, err)
+}
@@ -17,12 +17,18 @@ package server
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 
 	nexusapi "github.com/m88i/aicura/nexus"
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
+	"github.com/m88i/nexus-operator/pkg/framework"
 	"github.com/m88i/nexus-operator/pkg/logger"
+	"github.com/operator-framework/operator-sdk/pkg/status"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -30,7 +36,9 @@ import (
 type server struct {
 	nexus     *v1alpha1.Nexus
 	k8sclient client.Client
+	scheme    *runtime.Scheme
 	nexuscli  *nexusapi.Client
+	statuscli statusAPI
 	status    *v1alpha1.OperationsStatus
 }
 
@@ -39,25 +47,64 @@ const (
 	defaultAdminPassword = "admin123"
 	// used when running the operator instance locally
 	serverURLEnvKey = "NEXUS_SERVER_URL"
+	// clusterDomainEnvKey is the env var used to override the cluster domain used to resolve the instances services
+	clusterDomainEnvKey  = "CLUSTER_DOMAIN"
+	defaultClusterDomain = "cluster.local"
+
+	writableConditionType = "Writable"
+	healthyReason         = "Healthy"
+	unhealthyReason       = "Unhealthy"
 )
 
 var log = logger.GetLogger("server_operations")
 
-func handleServerOperations(nexus *v1alpha1.Nexus, client client.Client, nexusAPIBuilder func(url, user, pass string) *nexusapi.Client) (v1alpha1.OperationsStatus, error) {
-	s := server{nexus: nexus, k8sclient: client, status: &v1alpha1.OperationsStatus{}}
+func handleServerOperations(nexus *v1alpha1.Nexus, client client.Client, scheme *runtime.Scheme, nexusAPIBuilder func(url, user, pass string, httpClient *http.Client) *nexusapi.Client, statusAPIBuilder func(url, user, pass string, httpClient *http.Client) statusAPI) (v1alpha1.OperationsStatus, error) {
+	status, err := performServerOperations(nexus, client, scheme, nexusAPIBuilder, statusAPIBuilder)
+	if err != nil {
+		return status, err
+	}
+	scheduleRetry(nexus, &status)
+	return status, nil
+}
+
+func performServerOperations(nexus *v1alpha1.Nexus, client client.Client, scheme *runtime.Scheme, nexusAPIBuilder func(url, user, pass string, httpClient *http.Client) *nexusapi.Client, statusAPIBuilder func(url, user, pass string, httpClient *http.Client) statusAPI) (v1alpha1.OperationsStatus, error) {
+	s := server{nexus: nexus, k8sclient: client, scheme: scheme, status: &v1alpha1.OperationsStatus{}}
+	// keep the ports exposed while the server can't be reached, dropping them from the Service in the meantime does no good
+	if nexus.Spec.ServerOperations.ExposeDockerConnectors {
+		s.status.DockerConnectorPorts = nexus.Status.ServerOperationsStatus.DockerConnectorPorts
+	}
 	if nexus.Spec.GenerateRandomAdminPassword {
 		return *s.status, nil
 	}
 	log.Debugf("Initializing server operations in instance %s", nexus.Name)
+	if !s.hasAvailableReplicas() {
+		return *s.status, nil
+	}
+	hash, err := configFingerprint(nexus)
+	if err != nil {
+		return *s.status, err
+	}
+	if isUpToDate(nexus, hash) {
+		// the Docker connectors found before are carried forward in the status, new ones are picked up on the next resync
+		log.Debugf("Server configuration for instance %s hasn't changed since %s, skipping server operations", nexus.Name, nexus.Status.ServerOperationsStatus.LastAppliedTime)
+		return nexus.Status.ServerOperationsStatus, nil
+	}
+	internalEndpoint, err := s.getNexusEndpoint()
+	if err != nil {
+		s.status.Reason = fmt.Sprintf("Impossible to resolve endpoint for Nexus instance %s. Error: %s", nexus.Name, err.Error())
+		s.status.ServerReady = false
+		return *s.status, nil
+	}
+	httpClient, err := s.newHTTPClient()
+	if err != nil {
+		s.status.Reason = fmt.Sprintf("Impossible to configure TLS for Nexus instance %s. Error: %s", nexus.Name, err.Error())
+		s.status.ServerReady = false
+		return *s.status, nil
+	}
+	s.nexuscli = nexusAPIBuilder(internalEndpoint, defaultAdminUsername, defaultAdminPassword, httpClient)
+	user, pass := s.getStatusCredentials()
+	s.statuscli = statusAPIBuilder(internalEndpoint, user, pass, httpClient)
 	if s.isServerReady() {
-		internalEndpoint, err := s.getNexusEndpoint()
-		if err != nil {
-			s.status.Reason = fmt.Sprintf("Impossible to resolve endpoint for Nexus instance %s. Error: %s", nexus.Name, err.Error())
-			s.status.ServerReady = false
-			return *s.status, nil
-		}
-		s.nexuscli = nexusAPIBuilder(internalEndpoint, defaultAdminUsername, defaultAdminPassword)
-
 		if err := userOperations(&s).EnsureOperatorUser(); err != nil {
 			s.status.Reason = err.Error()
 			return *s.status, err
@@ -66,16 +113,23 @@ func handleServerOperations(nexus *v1alpha1.Nexus, client client.Client, nexusAP
 			s.status.Reason = err.Error()
 			return *s.status, err
 		}
+		if err := s.ensureDockerConnectorPorts(); err != nil {
+			s.status.Reason = err.Error()
+			return *s.status, err
+		}
 		s.status.Reason = ""
+		s.status.ConfigHash = hash
+		now := metav1.Now()
+		s.status.LastAppliedTime = &now
 	}
 	return *s.status, nil
 }
 
 // HandleServerOperations makes all required operations in the Nexus server side, such as creating the operator user
-func HandleServerOperations(nexus *v1alpha1.Nexus, client client.Client) (v1alpha1.OperationsStatus, error) {
-	return handleServerOperations(nexus, client, func(url, user, pass string) *nexusapi.Client {
-		return nexusapi.NewClient(url).WithCredentials(user, pass).Build()
-	})
+func HandleServerOperations(nexus *v1alpha1.Nexus, client client.Client, scheme *runtime.Scheme) (v1alpha1.OperationsStatus, error) {
+	return handleServerOperations(nexus, client, scheme, func(url, user, pass string, httpClient *http.Client) *nexusapi.Client {
+		return nexusapi.NewClient(url).WithCredentials(user, pass).WithHTTPClient(httpClient).Build()
+	}, newStatusClient)
 }
 
 func (s *server) getNexusEndpoint() (string, error) {
@@ -88,17 +142,115 @@ func (s *server) getNexusEndpoint() (string, error) {
 	if err := s.k8sclient.Get(context.TODO(), types.NamespacedName{Name: s.nexus.Name, Namespace: s.nexus.Namespace}, svc); err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("http://%s:%s", svc.Name, svc.Spec.Ports[0].TargetPort.String()), nil
+	scheme := "http"
+	if s.nexus.Spec.ServerOperations.TLS.Enabled {
+		scheme = "https"
+	}
+	// fully qualified, since the operator might not be running in the same namespace as the instance
+	return fmt.Sprintf("%s://%s.%s.svc.%s:%d", scheme, svc.Name, svc.Namespace, getClusterDomain(), svc.Spec.Ports[0].Port), nil
 }
 
-// isServerReady checks if the given Nexus instance is ready to receive requests
-func (s *server) isServerReady() bool {
+// hasAvailableReplicas checks if the Deployment reports any available replica, there's no point in reaching the server otherwise
+func (s *server) hasAvailableReplicas() bool {
 	if s.nexus.Status.DeploymentStatus.AvailableReplicas > 0 {
-		s.status.ServerReady = true
-		s.status.Reason = ""
 		return true
 	}
 	s.status.ServerReady = false
 	s.status.Reason = "Server does not have enough availble replicas"
 	return false
 }
+
+// isServerReady checks if the given Nexus instance is ready to receive requests by querying its status API.
+// The result of each system check is exposed as a condition in the Nexus status.
+func (s *server) isServerReady() bool {
+	writable, err := s.statuscli.IsWritable()
+	if err != nil {
+		s.setNotReady(fmt.Sprintf("Failed to verify if server is writable: %v", err))
+		return false
+	}
+	if !writable && s.nexus.Spec.FreezeOnShutdown {
+		writable = s.releaseShutdownFreeze()
+	}
+	s.setCondition(writableConditionType, writable, "")
+	if !writable {
+		s.setNotReady("Server is not able to serve read and write requests yet")
+		return false
+	}
+
+	checks, err := s.statuscli.SystemChecks()
+	if err == errStatusUnauthorized {
+		// the server is writable, we just can't see the details
+		log.Warnf("Not authorized to read system checks from Nexus instance %s, skipping", s.nexus.Name)
+	} else if err != nil {
+		s.setNotReady(fmt.Sprintf("Failed to fetch server system checks: %v", err))
+		return false
+	}
+	for name, check := range checks {
+		s.setCondition(checkConditionType(name), check.Healthy, check.Message)
+	}
+
+	s.status.ServerReady = true
+	s.status.Reason = ""
+	return true
+}
+
+// releaseShutdownFreeze takes the server out of the read-only mode left behind by the preStop hook, since it persists across restarts.
+// Only freezes recorded by the Operator when stopping the pods are released, those initiated by the server itself or by an administrator
+// are left alone. Returns true if the server was released.
+func (s *server) releaseShutdownFreeze() bool {
+	if !s.nexus.Status.ShutdownFrozen {
+		return false
+	}
+	state, err := s.statuscli.ReadOnlyState()
+	if err != nil {
+		log.Warnf("Unable to fetch the read-only state of Nexus instance %s: %v", s.nexus.Name, err)
+		return false
+	}
+	if !state.Frozen || state.SystemInitiated {
+		return false
+	}
+	if err := s.statuscli.Release(); err != nil {
+		log.Warnf("Unable to release the read-only mode of Nexus instance %s: %v", s.nexus.Name, err)
+		return false
+	}
+	s.nexus.Status.ShutdownFrozen = false
+	log.Infof("Released the read-only mode of Nexus instance %s", s.nexus.Name)
+	s.createInfoEvent(readOnlyReleasedReason, "Released the read-only mode set when the server was shut down")
+	return true
+}
+
+func (s *server) setNotReady(reason string) {
+	s.status.ServerReady = false
+	s.status.Reason = reason
+}
+
+func (s *server) setCondition(condType string, healthy bool, message string) {
+	condition := status.Condition{
+		Type:    status.ConditionType(condType),
+		Status:  corev1.ConditionTrue,
+		Reason:  healthyReason,
+		Message: message,
+	}
+	if !healthy {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = unhealthyReason
+	}
+	s.nexus.Status.Conditions.SetCondition(condition)
+}
+
+// getStatusCredentials resolves the credentials used to query the status API, preferring the operator user
+func (s *server) getStatusCredentials() (user, password string) {
+	user, password, err := s.getOperatorUserCredentials()
+	if err != nil || len(user) == 0 || len(password) == 0 {
+		return defaultAdminUsername, defaultAdminPassword
+	}
+	return user, password
+}
+
+func (s *server) getOperatorUserCredentials() (user, password string, err error) {
+	secret := &corev1.Secret{}
+	if err := framework.Fetch(s.k8sclient, framework.Key(s.nexus), secret); err != nil {
+		return "", "", err
+	}
+	return string(secret.Data[meta.SecretKeyUsername]), string(secret.Data[meta.SecretKeyPassword]), nil
+}
@@ -15,8 +15,12 @@
 package server
 
 import (
+	"fmt"
+	"net/http"
 	"net/url"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/m88i/aicura/nexus"
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
@@ -43,17 +47,72 @@ func createNewServerAndKubeCli(t *testing.T, objects ...runtime.Object) (*server
 	server := &server{
 		nexus:     nexusInstance,
 		k8sclient: client,
+		scheme:    client.Scheme(),
 		nexuscli:  nexus.NewFakeClient(),
+		statuscli: &fakeStatusAPI{writable: true},
 		status:    &v1alpha1.OperationsStatus{},
 	}
 
 	return server, client
 }
 
-func nexusAPIFakeBuilder(url, user, pass string) *nexus.Client {
+func nexusAPIFakeBuilder(url, user, pass string, httpClient *http.Client) *nexus.Client {
 	return nexus.NewFakeClient()
 }
 
+func statusAPIFakeBuilder(url, user, pass string, httpClient *http.Client) statusAPI {
+	return &fakeStatusAPI{writable: true, checks: map[string]systemCheck{"Blob Stores Ready": {Healthy: true, Message: "All blob stores are ready"}}}
+}
+
+type fakeStatusAPI struct {
+	writable    bool
+	writableErr error
+	checks      map[string]systemCheck
+	checksErr   error
+	readOnly    readOnlyState
+	readOnlyErr error
+	freezeErr   error
+	releaseErr  error
+	released    bool
+	dockerRepos []dockerRepository
+	dockerErr   error
+}
+
+func (f *fakeStatusAPI) IsWritable() (bool, error) {
+	return f.writable, f.writableErr
+}
+
+func (f *fakeStatusAPI) SystemChecks() (map[string]systemCheck, error) {
+	return f.checks, f.checksErr
+}
+
+func (f *fakeStatusAPI) ReadOnlyState() (readOnlyState, error) {
+	return f.readOnly, f.readOnlyErr
+}
+
+func (f *fakeStatusAPI) Freeze() error {
+	if f.freezeErr != nil {
+		return f.freezeErr
+	}
+	f.readOnly = readOnlyState{Frozen: true, SummaryReason: "Activated by an administrator"}
+	f.writable = false
+	return nil
+}
+
+func (f *fakeStatusAPI) Release() error {
+	if f.releaseErr != nil {
+		return f.releaseErr
+	}
+	f.released = true
+	f.readOnly = readOnlyState{}
+	f.writable = true
+	return nil
+}
+
+func (f *fakeStatusAPI) DockerRepositories() ([]dockerRepository, error) {
+	return f.dockerRepos, f.dockerErr
+}
+
 func Test_server_getNexusEndpoint(t *testing.T) {
 	nexus := &v1alpha1.Nexus{
 		Spec:       v1alpha1.NexusSpec{},
@@ -88,6 +147,15 @@ func Test_server_getNexusEndpoint(t *testing.T) {
 	assert.Contains(t, URL, "8081")
 	_, err = url.Parse(URL)
 	assert.NoError(t, err)
+	assert.Equal(t, "http://nexus3."+t.Name()+".svc.cluster.local:8081", URL)
+
+	// HTTPS with a custom cluster domain
+	nexus.Spec.ServerOperations.TLS.Enabled = true
+	_ = os.Setenv(clusterDomainEnvKey, "corp.internal")
+	defer os.Unsetenv(clusterDomainEnvKey)
+	URL, err = s.getNexusEndpoint()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://nexus3."+t.Name()+".svc.corp.internal:8081", URL)
 }
 
 func Test_server_getNexusEndpointNoURL(t *testing.T) {
@@ -116,8 +184,21 @@ func Test_server_isServerReady(t *testing.T) {
 			},
 		},
 	}
-	s := server{nexus: nexus, status: &v1alpha1.OperationsStatus{}}
+	s := server{nexus: nexus, status: &v1alpha1.OperationsStatus{}, statuscli: &fakeStatusAPI{
+		writable: true,
+		checks: map[string]systemCheck{
+			"Blob Stores Ready":         {Healthy: true, Message: "All blob stores are ready"},
+			"Default Admin Credentials": {Healthy: false, Message: "The default admin credentials have not been changed"},
+			"File Descriptors":          {Healthy: true},
+		},
+	}}
 	assert.True(t, s.isServerReady())
+	assert.True(t, s.status.ServerReady)
+	assert.True(t, nexus.Status.Conditions.IsTrueFor(writableConditionType))
+	assert.True(t, nexus.Status.Conditions.IsTrueFor("BlobStoresReady"))
+	assert.True(t, nexus.Status.Conditions.IsTrueFor("FileDescriptors"))
+	assert.True(t, nexus.Status.Conditions.IsFalseFor("DefaultAdminCredentials"))
+	assert.Equal(t, "The default admin credentials have not been changed", nexus.Status.Conditions.GetCondition("DefaultAdminCredentials").Message)
 }
 
 func Test_server_serverNotReady(t *testing.T) {
@@ -126,7 +207,78 @@ func Test_server_serverNotReady(t *testing.T) {
 		ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
 	}
 	s := server{nexus: nexus, status: &v1alpha1.OperationsStatus{}}
+	assert.False(t, s.hasAvailableReplicas())
+	assert.NotEmpty(t, s.status.Reason)
+}
+
+func Test_server_serverNotWritable(t *testing.T) {
+	nexus := &v1alpha1.Nexus{ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}}
+	s := server{nexus: nexus, status: &v1alpha1.OperationsStatus{}, statuscli: &fakeStatusAPI{writable: false}}
+	assert.False(t, s.isServerReady())
+	assert.False(t, s.status.ServerReady)
+	assert.NotEmpty(t, s.status.Reason)
+	assert.True(t, nexus.Status.Conditions.IsFalseFor(writableConditionType))
+
+	s = server{nexus: nexus, status: &v1alpha1.OperationsStatus{}, statuscli: &fakeStatusAPI{writableErr: fmt.Errorf("connection refused")}}
 	assert.False(t, s.isServerReady())
+	assert.Contains(t, s.status.Reason, "connection refused")
+}
+
+func Test_server_releaseShutdownFreeze(t *testing.T) {
+	nexus := &v1alpha1.Nexus{ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}, Spec: v1alpha1.NexusSpec{FreezeOnShutdown: true}}
+	nexus.Status.ShutdownFrozen = true
+	cli := test.NewFakeClientBuilder(nexus).Build()
+
+	// frozen by the preStop hook
+	statusAPI := &fakeStatusAPI{readOnly: readOnlyState{Frozen: true}}
+	s := server{nexus: nexus, k8sclient: cli, scheme: cli.Scheme(), status: &v1alpha1.OperationsStatus{}, statuscli: statusAPI}
+	assert.True(t, s.isServerReady())
+	assert.True(t, statusAPI.released)
+	assert.False(t, nexus.Status.ShutdownFrozen)
+	assert.True(t, nexus.Status.Conditions.IsTrueFor(writableConditionType))
+	assert.True(t, test.EventExists(cli, readOnlyReleasedReason))
+
+	// frozen by someone else, the Operator didn't stop the pods
+	statusAPI = &fakeStatusAPI{readOnly: readOnlyState{Frozen: true}}
+	s.statuscli = statusAPI
+	assert.False(t, s.isServerReady())
+	assert.False(t, statusAPI.released)
+
+	// frozen by the server itself
+	nexus.Status.ShutdownFrozen = true
+	statusAPI = &fakeStatusAPI{readOnly: readOnlyState{Frozen: true, SystemInitiated: true}}
+	s.statuscli = statusAPI
+	assert.False(t, s.isServerReady())
+	assert.False(t, statusAPI.released)
+
+	// not frozen, just not writable yet
+	statusAPI = &fakeStatusAPI{}
+	s.statuscli = statusAPI
+	assert.False(t, s.isServerReady())
+	assert.False(t, statusAPI.released)
+
+	// unable to release
+	statusAPI = &fakeStatusAPI{readOnly: readOnlyState{Frozen: true}, releaseErr: fmt.Errorf("forbidden")}
+	s.statuscli = statusAPI
+	assert.False(t, s.isServerReady())
+	assert.True(t, nexus.Status.ShutdownFrozen)
+
+	// the hook isn't enabled, so the freeze isn't ours
+	nexus.Spec.FreezeOnShutdown = false
+	statusAPI = &fakeStatusAPI{readOnly: readOnlyState{Frozen: true}}
+	s.statuscli = statusAPI
+	assert.False(t, s.isServerReady())
+	assert.False(t, statusAPI.released)
+}
+
+func Test_server_serverReadyChecksUnauthorized(t *testing.T) {
+	nexus := &v1alpha1.Nexus{ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}}
+	s := server{nexus: nexus, status: &v1alpha1.OperationsStatus{}, statuscli: &fakeStatusAPI{writable: true, checksErr: errStatusUnauthorized}}
+	assert.True(t, s.isServerReady())
+
+	s = server{nexus: nexus, status: &v1alpha1.OperationsStatus{}, statuscli: &fakeStatusAPI{writable: true, checksErr: fmt.Errorf("unexpected response")}}
+	assert.False(t, s.isServerReady())
+	assert.NotEmpty(t, s.status.Reason)
 }
 
 func Test_HandleServerOperationsNoFake(t *testing.T) {
@@ -136,7 +288,7 @@ func Test_HandleServerOperationsNoFake(t *testing.T) {
 	}
 	cli := test.NewFakeClientBuilder(nexus).Build()
 
-	status, err := HandleServerOperations(nexus, cli)
+	status, err := HandleServerOperations(nexus, cli, cli.Scheme())
 	assert.NoError(t, err)
 	assert.False(t, status.ServerReady)
 }
@@ -169,12 +321,13 @@ func Test_handleServerOperations(t *testing.T) {
 		},
 	}
 	cli := test.NewFakeClientBuilder(nexus, svc, &corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: nexus.Name, Namespace: nexus.Namespace}}).Build()
-	status, err := handleServerOperations(nexus, cli, nexusAPIFakeBuilder)
+	status, err := handleServerOperations(nexus, cli, cli.Scheme(), nexusAPIFakeBuilder, statusAPIFakeBuilder)
 	assert.NoError(t, err)
 	assert.NotNil(t, status)
 	assert.True(t, status.CommunityRepositoriesCreated)
 	assert.True(t, status.OperatorUserCreated)
 	assert.True(t, status.ServerReady)
+	assert.True(t, nexus.Status.Conditions.IsTrueFor("BlobStoresReady"))
 	// see: https://github.com/m88i/aicura/issues/18
 	assert.False(t, status.MavenCentralUpdated)
 }
@@ -190,7 +343,7 @@ func Test_handleServerOperationsNoEndpoint(t *testing.T) {
 		},
 	}
 	cli := test.NewFakeClientBuilder(nexus).Build()
-	status, err := handleServerOperations(nexus, cli, nexusAPIFakeBuilder)
+	status, err := handleServerOperations(nexus, cli, cli.Scheme(), nexusAPIFakeBuilder, statusAPIFakeBuilder)
 	assert.NoError(t, err)
 	assert.NotNil(t, status)
 	assert.False(t, status.CommunityRepositoriesCreated)
@@ -200,3 +353,50 @@ func Test_handleServerOperationsNoEndpoint(t *testing.T) {
 	// see: https://github.com/m88i/aicura/issues/18
 	assert.False(t, status.MavenCentralUpdated)
 }
+
+func Test_handleServerOperationsUpToDate(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		Spec:       v1alpha1.NexusSpec{ServerOperations: v1alpha1.ServerOperationsOpts{ResyncPeriodSeconds: 600}},
+		ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Status: v1alpha1.NexusStatus{
+			DeploymentStatus: appv1.DeploymentStatus{
+				AvailableReplicas: 1,
+			},
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: meta.DefaultObjectMeta(nexus),
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 8081, TargetPort: intstr.FromInt(8081)}},
+		},
+	}
+	cli := test.NewFakeClientBuilder(nexus, svc, &corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: nexus.Name, Namespace: nexus.Namespace}}).Build()
+	status, err := handleServerOperations(nexus, cli, cli.Scheme(), nexusAPIFakeBuilder, statusAPIFakeBuilder)
+	assert.NoError(t, err)
+	assert.True(t, status.ServerReady)
+	assert.NotEmpty(t, status.ConfigHash)
+	assert.NotNil(t, status.LastAppliedTime)
+	nexus.Status.ServerOperationsStatus = status
+
+	// nothing changed, the server must not be reached
+	notWritable := func(url, user, pass string, httpClient *http.Client) statusAPI {
+		return &fakeStatusAPI{writable: false}
+	}
+	skipped, err := handleServerOperations(nexus, cli, cli.Scheme(), nexusAPIFakeBuilder, notWritable)
+	assert.NoError(t, err)
+	assert.Equal(t, status, skipped)
+
+	// configuration changed
+	nexus.Spec.ServerOperations.DisableRepositoryCreation = true
+	changed, err := handleServerOperations(nexus, cli, cli.Scheme(), nexusAPIFakeBuilder, notWritable)
+	assert.NoError(t, err)
+	assert.False(t, changed.ServerReady)
+	nexus.Spec.ServerOperations.DisableRepositoryCreation = false
+
+	// resync period expired
+	expired := v1.NewTime(status.LastAppliedTime.Add(-time.Hour))
+	nexus.Status.ServerOperationsStatus.LastAppliedTime = &expired
+	resynced, err := handleServerOperations(nexus, cli, cli.Scheme(), nexusAPIFakeBuilder, notWritable)
+	assert.NoError(t, err)
+	assert.False(t, resynced.ServerReady)
+}
@@ -15,6 +15,8 @@
 package server
 
 import (
+	"sort"
+
 	"github.com/m88i/aicura/nexus"
 )
 
@@ -82,11 +84,14 @@ func (r *repositoryOperation) addCommunityReposToMavenCentralGroup() error {
 		mavenCentral.Group.MemberNames = append(mavenCentral.Group.MemberNames, newMembers...)
 
 		err = r.nexuscli.MavenGroupRepositoryService.Update(*mavenCentral)
-		if err == nil {
-			log.Debug("Maven Central updated with new community members")
-			r.status.MavenCentralUpdated = true
+		if err != nil {
+			r.createWarningEvent(mavenCentralGroupUpdateFailedReason, "Failed to add %v to the '%s' group: %v", newMembers, mavenCentralRepoID, err)
+			return err
 		}
-		return err
+		log.Debug("Maven Central updated with new community members")
+		r.createInfoEvent(mavenCentralGroupUpdatedReason, "Added %v to the '%s' group", newMembers, mavenCentralRepoID)
+		r.status.MavenCentralUpdated = true
+		return nil
 	}
 	log.Debug("Community repositories already added to the Maven Central repo")
 	r.status.MavenCentralUpdated = true
@@ -109,15 +114,26 @@ func (r *repositoryOperation) createCommunityReposIfNotExists() error {
 	if len(reposToAdd) > 0 {
 		log.Debugf("Repositories to add %v", reposToAdd)
 		if err := r.nexuscli.MavenProxyRepositoryService.Add(reposToAdd...); err != nil {
+			r.createWarningEvent(repositoriesCreationFailedReason, "Failed to create proxy repositories %v: %v", repositoryNames(reposToAdd), err)
 			return err
 		}
 		log.Debug("All repositories created")
+		r.createInfoEvent(repositoriesCreatedReason, "Created proxy repositories %v", repositoryNames(reposToAdd))
 	}
 	log.Debug("Community repositories already created, skipping")
 	r.status.CommunityRepositoriesCreated = true
 	return nil
 }
 
+func repositoryNames(repos []nexus.MavenProxyRepository) []string {
+	names := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		names = append(names, repo.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func defaultMavenProxyInstance(name, url string) nexus.MavenProxyRepository {
 	return nexus.MavenProxyRepository{
 		Proxy: nexus.Proxy{
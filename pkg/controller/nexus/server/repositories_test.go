@@ -17,6 +17,7 @@ package server
 import (
 	"testing"
 
+	"github.com/m88i/aicura/nexus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,3 +31,8 @@ func TestAddCommReposNoCentralGroup(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, repos, len(communityMavenProxies))
 }
+
+func Test_repositoryNames(t *testing.T) {
+	repos := []nexus.MavenProxyRepository{defaultMavenProxyInstance("jboss", ""), defaultMavenProxyInstance("apache", "")}
+	assert.Equal(t, []string{"apache", "jboss"}, repositoryNames(repos))
+}
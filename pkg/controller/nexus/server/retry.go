@@ -0,0 +1,72 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"time"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	retryBaseInterval = 5 * time.Second
+	retryMaxInterval  = 5 * time.Minute
+)
+
+// RequeueAfter returns how long the reconciler should wait before attempting the pending server operations again.
+// If there are no pending operations, returns the time left until they're resynced or 0 if they haven't succeeded yet.
+func RequeueAfter(nexus *v1alpha1.Nexus) time.Duration {
+	status := nexus.Status.ServerOperationsStatus
+	if status.NextRetryTime == nil {
+		return untilResync(nexus)
+	}
+	if wait := time.Until(status.NextRetryTime.Time); wait > 0 {
+		return wait
+	}
+	return retryBaseInterval
+}
+
+// scheduleRetry tracks the attempts to perform pending server operations, backing off exponentially between each one of them.
+// Reconciliations happening before the scheduled retry keep the current attempt, otherwise every event would push the retry further away.
+func scheduleRetry(nexus *v1alpha1.Nexus, status *v1alpha1.OperationsStatus) {
+	if status.ServerReady || nexus.Spec.GenerateRandomAdminPassword {
+		status.Attempts = 0
+		status.NextRetryTime = nil
+		return
+	}
+	previous := nexus.Status.ServerOperationsStatus
+	if previous.NextRetryTime != nil && time.Now().Before(previous.NextRetryTime.Time) {
+		status.Attempts = previous.Attempts
+		status.NextRetryTime = previous.NextRetryTime
+		return
+	}
+	status.Attempts = previous.Attempts + 1
+	next := metav1.NewTime(time.Now().Add(retryInterval(status.Attempts)))
+	status.NextRetryTime = &next
+	log.Debugf("Server operations for instance %s are pending (attempt %d), retrying at %s", nexus.Name, status.Attempts, next)
+}
+
+// retryInterval calculates the interval before the given attempt: 5s, 10s, 20s... up to 5 minutes
+func retryInterval(attempts int32) time.Duration {
+	interval := retryBaseInterval
+	for i := int32(1); i < attempts; i++ {
+		interval *= 2
+		if interval >= retryMaxInterval {
+			return retryMaxInterval
+		}
+	}
+	return interval
+}
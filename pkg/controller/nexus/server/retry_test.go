@@ -0,0 +1,76 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_retryInterval(t *testing.T) {
+	assert.Equal(t, 5*time.Second, retryInterval(1))
+	assert.Equal(t, 10*time.Second, retryInterval(2))
+	assert.Equal(t, 20*time.Second, retryInterval(3))
+	assert.Equal(t, retryMaxInterval, retryInterval(10))
+	assert.Equal(t, retryMaxInterval, retryInterval(1000))
+}
+
+func Test_scheduleRetry(t *testing.T) {
+	nexus := &v1alpha1.Nexus{ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()}}
+	nexus.Status.ServerOperationsStatus.Attempts = 2
+
+	status := &v1alpha1.OperationsStatus{ServerReady: false}
+	scheduleRetry(nexus, status)
+	assert.Equal(t, int32(3), status.Attempts)
+	assert.NotNil(t, status.NextRetryTime)
+	wait := RequeueAfter(&v1alpha1.Nexus{Status: v1alpha1.NexusStatus{ServerOperationsStatus: *status}})
+	assert.True(t, wait > 15*time.Second && wait <= 20*time.Second)
+
+	// the previous retry hasn't been reached yet, so the attempt is kept
+	nexus.Status.ServerOperationsStatus = *status.DeepCopy()
+	status = &v1alpha1.OperationsStatus{ServerReady: false}
+	scheduleRetry(nexus, status)
+	assert.Equal(t, int32(3), status.Attempts)
+	assert.Equal(t, nexus.Status.ServerOperationsStatus.NextRetryTime, status.NextRetryTime)
+
+	// once it's reached, we back off a bit more
+	past := v1.NewTime(time.Now().Add(-time.Second))
+	nexus.Status.ServerOperationsStatus.NextRetryTime = &past
+	status = &v1alpha1.OperationsStatus{ServerReady: false}
+	scheduleRetry(nexus, status)
+	assert.Equal(t, int32(4), status.Attempts)
+	assert.True(t, status.NextRetryTime.After(time.Now()))
+
+	status = &v1alpha1.OperationsStatus{ServerReady: true}
+	scheduleRetry(nexus, status)
+	assert.Equal(t, int32(0), status.Attempts)
+	assert.Nil(t, status.NextRetryTime)
+	assert.Equal(t, time.Duration(0), RequeueAfter(&v1alpha1.Nexus{Status: v1alpha1.NexusStatus{ServerOperationsStatus: *status}}))
+
+	// nothing to wait for when server operations are disabled
+	nexus.Spec.GenerateRandomAdminPassword = true
+	status = &v1alpha1.OperationsStatus{}
+	scheduleRetry(nexus, status)
+	assert.Nil(t, status.NextRetryTime)
+}
+
+func Test_RequeueAfterPastRetryTime(t *testing.T) {
+	past := v1.NewTime(time.Now().Add(-time.Minute))
+	assert.Equal(t, retryBaseInterval, RequeueAfter(&v1alpha1.Nexus{Status: v1alpha1.NexusStatus{ServerOperationsStatus: v1alpha1.OperationsStatus{NextRetryTime: &past}}}))
+}
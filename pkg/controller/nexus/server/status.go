@@ -0,0 +1,188 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+const (
+	statusWritablePath  = "/service/rest/v1/status/writable"
+	statusCheckPath     = "/service/rest/v1/status/check"
+	readOnlyPath        = "/service/rest/v1/read-only"
+	readOnlyReleasePath = readOnlyPath + "/release"
+	readOnlyFreezePath  = readOnlyPath + "/freeze"
+)
+
+// errStatusUnauthorized is returned when the credentials in use are not allowed to query the system checks
+var errStatusUnauthorized = fmt.Errorf("not authorized to read the server system checks")
+
+// systemCheck is the result of a single Nexus system check as reported by the status API
+type systemCheck struct {
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message"`
+}
+
+// readOnlyState is the read-only mode of the server as reported by the read-only API
+type readOnlyState struct {
+	Frozen bool `json:"frozen"`
+	// SystemInitiated is set when the server froze itself, such as when a blob store runs out of space
+	SystemInitiated bool   `json:"systemInitiated"`
+	SummaryReason   string `json:"summaryReason"`
+}
+
+// statusAPI describes the Nexus server status endpoints, which are not covered by the Nexus API client
+type statusAPI interface {
+	// IsWritable verifies if the server can serve read and write requests
+	IsWritable() (bool, error)
+	// SystemChecks fetches the result of every system check performed by the server, indexed by the check name
+	SystemChecks() (map[string]systemCheck, error)
+	// ReadOnlyState fetches the read-only mode of the server
+	ReadOnlyState() (readOnlyState, error)
+	// Freeze puts the server in read-only mode, returning once the pending writes are done
+	Freeze() error
+	// Release takes the server out of the read-only mode
+	Release() error
+	// DockerRepositories fetches the Docker repositories along with their connector ports
+	DockerRepositories() ([]dockerRepository, error)
+}
+
+type statusClient struct {
+	baseURL  string
+	username string
+	password string
+	http     *http.Client
+}
+
+func newStatusClient(baseURL, username, password string, httpClient *http.Client) statusAPI {
+	return &statusClient{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		username: username,
+		password: password,
+		http:     httpClient,
+	}
+}
+
+func (s *statusClient) IsWritable() (bool, error) {
+	resp, err := s.get(statusWritablePath)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusServiceUnavailable:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected response from %s: %s", statusWritablePath, resp.Status)
+	}
+}
+
+func (s *statusClient) SystemChecks() (map[string]systemCheck, error) {
+	resp, err := s.get(statusCheckPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusServiceUnavailable:
+		// the server answers with 503 when any of the checks is unhealthy, the body is the same
+		checks := make(map[string]systemCheck)
+		if err := json.NewDecoder(resp.Body).Decode(&checks); err != nil {
+			return nil, fmt.Errorf("unable to decode response from %s: %v", statusCheckPath, err)
+		}
+		return checks, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, errStatusUnauthorized
+	default:
+		return nil, fmt.Errorf("unexpected response from %s: %s", statusCheckPath, resp.Status)
+	}
+}
+
+func (s *statusClient) ReadOnlyState() (readOnlyState, error) {
+	state := readOnlyState{}
+	resp, err := s.get(readOnlyPath)
+	if err != nil {
+		return state, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return state, fmt.Errorf("unexpected response from %s: %s", readOnlyPath, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return state, fmt.Errorf("unable to decode response from %s: %v", readOnlyPath, err)
+	}
+	return state, nil
+}
+
+func (s *statusClient) Freeze() error {
+	resp, err := s.do(http.MethodPost, readOnlyFreezePath)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	// the server answers with 404 when it was already frozen
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("unexpected response from %s: %s", readOnlyFreezePath, resp.Status)
+	}
+}
+
+func (s *statusClient) Release() error {
+	resp, err := s.do(http.MethodPost, readOnlyReleasePath)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	// the server answers with 404 when it wasn't frozen
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("unexpected response from %s: %s", readOnlyReleasePath, resp.Status)
+	}
+}
+
+func (s *statusClient) get(path string) (*http.Response, error) {
+	return s.do(http.MethodGet, path)
+}
+
+func (s *statusClient) do(method, path string) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(s.username, s.password)
+	req.Header.Set("Accept", "application/json")
+	return s.http.Do(req)
+}
+
+// checkConditionType converts a system check name, such as "Blob Stores Ready", into a condition type ("BlobStoresReady")
+func checkConditionType(checkName string) string {
+	var condType strings.Builder
+	for _, word := range strings.FieldsFunc(checkName, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		runes := []rune(word)
+		condType.WriteRune(unicode.ToUpper(runes[0]))
+		condType.WriteString(string(runes[1:]))
+	}
+	return condType.String()
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newStatusServer(t *testing.T, writableCode, checkCode int, checkBody string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, defaultAdminUsername, user)
+		assert.Equal(t, defaultAdminPassword, pass)
+		switch r.URL.Path {
+		case statusWritablePath:
+			w.WriteHeader(writableCode)
+		case statusCheckPath:
+			w.WriteHeader(checkCode)
+			_, _ = w.Write([]byte(checkBody))
+		case readOnlyPath:
+			_, _ = w.Write([]byte(`{"systemInitiated":false,"summaryReason":"Activated by an administrator","frozen":true}`))
+		case readOnlyReleasePath, readOnlyFreezePath:
+			assert.Equal(t, http.MethodPost, r.Method)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func Test_statusClient_IsWritable(t *testing.T) {
+	srv := newStatusServer(t, http.StatusOK, http.StatusOK, "{}")
+	defer srv.Close()
+	writable, err := newStatusClient(srv.URL+"/", defaultAdminUsername, defaultAdminPassword, http.DefaultClient).IsWritable()
+	assert.NoError(t, err)
+	assert.True(t, writable)
+
+	unavailable := newStatusServer(t, http.StatusServiceUnavailable, http.StatusOK, "{}")
+	defer unavailable.Close()
+	writable, err = newStatusClient(unavailable.URL, defaultAdminUsername, defaultAdminPassword, http.DefaultClient).IsWritable()
+	assert.NoError(t, err)
+	assert.False(t, writable)
+
+	broken := newStatusServer(t, http.StatusInternalServerError, http.StatusOK, "{}")
+	defer broken.Close()
+	_, err = newStatusClient(broken.URL, defaultAdminUsername, defaultAdminPassword, http.DefaultClient).IsWritable()
+	assert.Error(t, err)
+}
+
+func Test_statusClient_SystemChecks(t *testing.T) {
+	body := `{"Blob Stores Ready":{"healthy":true,"message":"All blob stores are ready"},"Default Admin Credentials":{"healthy":false,"message":"The default admin credentials have not been changed"}}`
+	// unhealthy checks make the server answer with 503
+	srv := newStatusServer(t, http.StatusOK, http.StatusServiceUnavailable, body)
+	defer srv.Close()
+	checks, err := newStatusClient(srv.URL, defaultAdminUsername, defaultAdminPassword, http.DefaultClient).SystemChecks()
+	assert.NoError(t, err)
+	assert.Len(t, checks, 2)
+	assert.True(t, checks["Blob Stores Ready"].Healthy)
+	assert.False(t, checks["Default Admin Credentials"].Healthy)
+	assert.Equal(t, "The default admin credentials have not been changed", checks["Default Admin Credentials"].Message)
+
+	unauthorized := newStatusServer(t, http.StatusOK, http.StatusForbidden, "")
+	defer unauthorized.Close()
+	_, err = newStatusClient(unauthorized.URL, defaultAdminUsername, defaultAdminPassword, http.DefaultClient).SystemChecks()
+	assert.Equal(t, errStatusUnauthorized, err)
+}
+
+func Test_statusClient_readOnly(t *testing.T) {
+	srv := newStatusServer(t, http.StatusServiceUnavailable, http.StatusOK, "{}")
+	defer srv.Close()
+	client := newStatusClient(srv.URL, defaultAdminUsername, defaultAdminPassword, http.DefaultClient)
+	state, err := client.ReadOnlyState()
+	assert.NoError(t, err)
+	assert.True(t, state.Frozen)
+	assert.False(t, state.SystemInitiated)
+	assert.Equal(t, "Activated by an administrator", state.SummaryReason)
+	assert.NoError(t, client.Freeze())
+	assert.NoError(t, client.Release())
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer broken.Close()
+	client = newStatusClient(broken.URL, defaultAdminUsername, defaultAdminPassword, http.DefaultClient)
+	_, err = client.ReadOnlyState()
+	assert.Error(t, err)
+	assert.Error(t, client.Freeze())
+	assert.Error(t, client.Release())
+}
+
+func Test_checkConditionType(t *testing.T) {
+	assert.Equal(t, "BlobStoresReady", checkConditionType("Blob Stores Ready"))
+	assert.Equal(t, "DefaultAdminCredentials", checkConditionType("Default Admin Credentials"))
+	assert.Equal(t, "LifecyclePhase", checkConditionType("Lifecycle Phase"))
+	assert.Equal(t, "ReadOnlyDetector", checkConditionType("Read-Only Detector"))
+}
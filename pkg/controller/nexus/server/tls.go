@@ -0,0 +1,82 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/m88i/nexus-operator/pkg/framework"
+	"github.com/m88i/nexus-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const serverRequestTimeout = 30 * time.Second
+
+func getClusterDomain() string {
+	return util.GetOSEnv(clusterDomainEnvKey, defaultClusterDomain)
+}
+
+// newHTTPClient creates the HTTP client used to reach the server, trusting the CA bundle referenced in the Nexus CR if any
+func (s *server) newHTTPClient() (*http.Client, error) {
+	httpClient := &http.Client{Timeout: serverRequestTimeout}
+	tlsSpec := s.nexus.Spec.ServerOperations.TLS
+	if !tlsSpec.Enabled || tlsSpec.CABundle == nil {
+		return httpClient, nil
+	}
+
+	bundle, err := s.getCABundle()
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("no valid PEM-encoded certificates found in the CA bundle")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	httpClient.Transport = transport
+	return httpClient, nil
+}
+
+// getCABundle fetches the CA bundle contents from the Secret or ConfigMap referenced in the Nexus CR
+func (s *server) getCABundle() ([]byte, error) {
+	source := s.nexus.Spec.ServerOperations.TLS.CABundle
+	if ref := source.SecretKeyRef; ref != nil {
+		secret := &corev1.Secret{}
+		if err := framework.Fetch(s.k8sclient, types.NamespacedName{Name: ref.Name, Namespace: s.nexus.Namespace}, secret); err != nil {
+			return nil, fmt.Errorf("unable to fetch CA bundle Secret %s: %v", ref.Name, err)
+		}
+		if bundle, ok := secret.Data[ref.Key]; ok {
+			return bundle, nil
+		}
+		return nil, fmt.Errorf("key %s not found in CA bundle Secret %s", ref.Key, ref.Name)
+	}
+	if ref := source.ConfigMapKeyRef; ref != nil {
+		cm := &corev1.ConfigMap{}
+		if err := framework.Fetch(s.k8sclient, types.NamespacedName{Name: ref.Name, Namespace: s.nexus.Namespace}, cm); err != nil {
+			return nil, fmt.Errorf("unable to fetch CA bundle ConfigMap %s: %v", ref.Name, err)
+		}
+		if bundle, ok := cm.Data[ref.Key]; ok {
+			return []byte(bundle), nil
+		}
+		return nil, fmt.Errorf("key %s not found in CA bundle ConfigMap %s", ref.Key, ref.Name)
+	}
+	return nil, fmt.Errorf("CA bundle references neither a Secret nor a ConfigMap")
+}
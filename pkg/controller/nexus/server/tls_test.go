@@ -0,0 +1,114 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_server_newHTTPClient(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer tlsServer.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+
+	cm := &corev1.ConfigMap{ObjectMeta: v1.ObjectMeta{Name: "ca", Namespace: t.Name()}, Data: map[string]string{"ca.crt": string(caBundle)}}
+	server, _ := createNewServerAndKubeCli(t, cm)
+
+	// plain HTTP
+	httpClient, err := server.newHTTPClient()
+	assert.NoError(t, err)
+	assert.Nil(t, httpClient.Transport)
+	_, err = newStatusClient(tlsServer.URL, defaultAdminUsername, defaultAdminPassword, httpClient).IsWritable()
+	assert.Error(t, err)
+
+	// HTTPS trusting the CA bundle from the ConfigMap
+	server.nexus.Spec.ServerOperations.TLS = v1alpha1.ServerOperationsTLS{
+		Enabled: true,
+		CABundle: &v1alpha1.NexusCABundleSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}, Key: "ca.crt"},
+		},
+	}
+	httpClient, err = server.newHTTPClient()
+	assert.NoError(t, err)
+	writable, err := newStatusClient(tlsServer.URL, defaultAdminUsername, defaultAdminPassword, httpClient).IsWritable()
+	assert.NoError(t, err)
+	assert.True(t, writable)
+}
+
+func Test_server_getCABundle(t *testing.T) {
+	secret := &corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: "ca", Namespace: t.Name()}, Data: map[string][]byte{"ca.crt": []byte("not a certificate")}}
+	server, _ := createNewServerAndKubeCli(t, secret)
+	server.nexus.Spec.ServerOperations.TLS = v1alpha1.ServerOperationsTLS{
+		Enabled: true,
+		CABundle: &v1alpha1.NexusCABundleSource{
+			SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}, Key: "ca.crt"},
+		},
+	}
+	bundle, err := server.getCABundle()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("not a certificate"), bundle)
+	_, err = server.newHTTPClient()
+	assert.Error(t, err)
+
+	server.nexus.Spec.ServerOperations.TLS.CABundle.SecretKeyRef.Key = "tls.crt"
+	_, err = server.getCABundle()
+	assert.Error(t, err)
+
+	server.nexus.Spec.ServerOperations.TLS.CABundle.SecretKeyRef.Name = "not-there"
+	_, err = server.getCABundle()
+	assert.Error(t, err)
+
+	server.nexus.Spec.ServerOperations.TLS.CABundle = &v1alpha1.NexusCABundleSource{
+		ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "not-there"}, Key: "ca.crt"},
+	}
+	_, err = server.getCABundle()
+	assert.Error(t, err)
+}
+
+func Test_handleServerOperationsCABundleNotFound(t *testing.T) {
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
+		Spec: v1alpha1.NexusSpec{ServerOperations: v1alpha1.ServerOperationsOpts{TLS: v1alpha1.ServerOperationsTLS{
+			Enabled: true,
+			CABundle: &v1alpha1.NexusCABundleSource{
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}, Key: "ca.crt"},
+			},
+		}}},
+		Status: v1alpha1.NexusStatus{DeploymentStatus: appv1.DeploymentStatus{AvailableReplicas: 1}},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: v1.ObjectMeta{Name: nexus.Name, Namespace: nexus.Namespace},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 8081}}},
+	}
+	server, cli := createNewServerAndKubeCli(t, svc)
+	server.nexus.Spec = nexus.Spec
+	server.nexus.Status = nexus.Status
+	status, err := handleServerOperations(server.nexus, cli, server.scheme, nexusAPIFakeBuilder, statusAPIFakeBuilder)
+	assert.NoError(t, err)
+	assert.False(t, status.ServerReady)
+	assert.Contains(t, status.Reason, "TLS")
+	assert.Contains(t, status.Reason, "Secret ca")
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/m88i/aicura/nexus"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
 	"github.com/m88i/nexus-operator/pkg/framework"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -31,11 +32,6 @@ const (
 	operatorLastName = "Operator"
 	defaultSource    = "default"
 	adminRole        = "nx-admin"
-
-	// SecretKeyPassword secret key for the Operator User in the Nexus server
-	SecretKeyPassword = "server-user-password"
-	// SecretKeyUsername secret key for the Operator Password in the Nexus server
-	SecretKeyUsername = "server-user-username"
 )
 
 type UserOperations interface {
@@ -92,13 +88,16 @@ func (u *userOperation) createOperatorUserIfNotExists() (*nexus.User, error) {
 	}
 	log.Debug("Trying to create operator user")
 	if err := u.nexuscli.UserService.Add(*user); err != nil {
+		u.createWarningEvent(operatorUserCreationFailedReason, "Failed to create operator user '%s': %v", operatorUsername, err)
 		return nil, err
 	}
 	if err := u.storeOperatorUserCredentials(user); err != nil {
 		//  TODO: in case of an error here, we should remove the user from the Nexus database. Edge case: an user could manually add the credentials later to the secret with a manually created user for us.
+		u.createWarningEvent(operatorUserCreationFailedReason, "Operator user '%s' created, but failed to store its credentials: %v", operatorUsername, err)
 		return nil, err
 	}
 	log.Debug("Operator user successfully created!")
+	u.createInfoEvent(operatorUserCreatedReason, "Operator user '%s' created", operatorUsername)
 	u.status.OperatorUserCreated = true
 	return user, nil
 }
@@ -112,8 +111,8 @@ func (u *userOperation) storeOperatorUserCredentials(user *nexus.User) error {
 	if secret.StringData == nil {
 		secret.StringData = make(map[string]string)
 	}
-	secret.StringData[SecretKeyPassword] = user.Password
-	secret.StringData[SecretKeyUsername] = user.UserID
+	secret.StringData[meta.SecretKeyPassword] = user.Password
+	secret.StringData[meta.SecretKeyUsername] = user.UserID
 	log.Debug("Updating secret with user credentials")
 	if err := u.k8sclient.Update(context.TODO(), secret); err != nil {
 		return err
@@ -121,14 +120,6 @@ func (u *userOperation) storeOperatorUserCredentials(user *nexus.User) error {
 	return nil
 }
 
-func (u *userOperation) getOperatorUserCredentials() (user, password string, err error) {
-	secret := &corev1.Secret{}
-	if err := framework.Fetch(u.k8sclient, framework.Key(u.nexus), secret); err != nil {
-		return "", "", err
-	}
-	return string(secret.Data[SecretKeyUsername]), string(secret.Data[SecretKeyPassword]), nil
-}
-
 func (u *userOperation) createOperatorUserInstance() (*nexus.User, error) {
 	password, err := u.generateRandomPassword()
 	if err != nil {
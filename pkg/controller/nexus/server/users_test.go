@@ -17,6 +17,8 @@ package server
 import (
 	"testing"
 
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
+	"github.com/m88i/nexus-operator/pkg/test"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -35,12 +37,12 @@ func Test_userOperation_EnsureOperatorUser(t *testing.T) {
 }
 
 func Test_userOperation_EnsureOperatorUser_AlreadyExists(t *testing.T) {
-	server, _ := createNewServerAndKubeCli(t,
+	server, client := createNewServerAndKubeCli(t,
 		&corev1.Secret{
 			ObjectMeta: v1.ObjectMeta{Name: "nexus3", Namespace: t.Name()},
 			Data: map[string][]byte{
-				SecretKeyPassword: []byte("12345"),
-				SecretKeyUsername: []byte(operatorUsername),
+				meta.SecretKeyPassword: []byte("12345"),
+				meta.SecretKeyUsername: []byte(operatorUsername),
 			}})
 
 	err := userOperations(server).EnsureOperatorUser()
@@ -50,4 +52,5 @@ func Test_userOperation_EnsureOperatorUser_AlreadyExists(t *testing.T) {
 	assert.NotNil(t, user)
 	assert.Equal(t, operatorUsername, user.UserID)
 	assert.True(t, server.status.OperatorUserCreated)
+	assert.False(t, test.EventExists(client, operatorUserCreatedReason))
 }
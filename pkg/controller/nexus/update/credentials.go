@@ -0,0 +1,131 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/m88i/nexus-operator/pkg/framework"
+	"github.com/m88i/nexus-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the same registry can be referenced by any of these hosts in a Docker config
+var dockerHubHosts = map[string]bool{
+	dockerHubDomain:        true,
+	"index.docker.io":      true,
+	"registry-1.docker.io": true,
+	dockerHubRegistryHost:  true,
+}
+
+// RegistryCredentials authenticate the requests to the registry when fetching the image tags
+type RegistryCredentials struct {
+	Username string
+	Password string
+}
+
+// dockerConfigEntry is a single registry entry in a Docker config file
+type dockerConfigEntry struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+// GetRegistryCredentials looks for credentials to the registry hosting the image repository (e.g. "docker.io/sonatype/nexus3") in the given image pull secrets.
+// Missing secrets are ignored. If no credentials are found, the requests to the registry are anonymous.
+func GetRegistryCredentials(c client.Client, namespace, repository string, pullSecrets []corev1.LocalObjectReference) (RegistryCredentials, error) {
+	registryURL, _ := registryAndPath(repository)
+	for _, ref := range pullSecrets {
+		secret := &corev1.Secret{}
+		if err := framework.Fetch(c, types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return RegistryCredentials{}, err
+		}
+		entries, err := dockerConfigEntries(secret)
+		if err != nil {
+			return RegistryCredentials{}, fmt.Errorf("unable to read image pull secret %s: %v", ref.Name, err)
+		}
+		for host, entry := range entries {
+			if sameRegistry(host, registryURL) {
+				return entry.credentials()
+			}
+		}
+	}
+	return RegistryCredentials{}, nil
+}
+
+func dockerConfigEntries(secret *corev1.Secret) (map[string]dockerConfigEntry, error) {
+	switch secret.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		config := struct {
+			Auths map[string]dockerConfigEntry `json:"auths"`
+		}{}
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+			return nil, err
+		}
+		return config.Auths, nil
+	case corev1.SecretTypeDockercfg:
+		entries := map[string]dockerConfigEntry{}
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigKey], &entries); err != nil {
+			return nil, err
+		}
+		return entries, nil
+	}
+	return nil, nil
+}
+
+func (e dockerConfigEntry) credentials() (RegistryCredentials, error) {
+	if len(e.Username) > 0 || len(e.Auth) == 0 {
+		return RegistryCredentials{Username: e.Username, Password: e.Password}, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(e.Auth)
+	if err != nil {
+		return RegistryCredentials{}, fmt.Errorf("invalid auth field: %v", err)
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return RegistryCredentials{}, fmt.Errorf("invalid auth field, expected 'username:password'")
+	}
+	return RegistryCredentials{Username: parts[0], Password: parts[1]}, nil
+}
+
+// sameRegistry verifies if both references point to the same registry, with or without scheme and path (e.g. "https://index.docker.io/v1/")
+func sameRegistry(reference, other string) bool {
+	referenceHost, otherHost := registryHost(reference), registryHost(other)
+	if dockerHubHosts[referenceHost] && dockerHubHosts[otherHost] {
+		return true
+	}
+	return referenceHost == otherHost
+}
+
+func registryHost(reference string) string {
+	if !strings.Contains(reference, "://") {
+		reference = util.HTTPSPrefixSchema + reference
+	}
+	parsed, err := url.Parse(reference)
+	if err != nil {
+		return reference
+	}
+	return parsed.Host
+}
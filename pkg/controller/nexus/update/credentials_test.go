@@ -0,0 +1,91 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/m88i/nexus-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetRegistryCredentials(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("alice:s3cr3t:with:colons"))
+	dockerConfigJSON := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub", Namespace: "ns"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://index.docker.io/v1/":{"auth":"` + auth + `"}}}`),
+		},
+	}
+	dockercfg := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "ns"},
+		Type:       corev1.SecretTypeDockercfg,
+		Data: map[string][]byte{
+			corev1.DockerConfigKey: []byte(`{"docker.io":{"username":"bob","password":"pass"}}`),
+		},
+	}
+	otherRegistry := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "quay", Namespace: "ns"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths":{"quay.io":{"username":"carol","password":"pass"}}}`),
+		},
+	}
+	invalid := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: "ns"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`not json`)},
+	}
+	client := test.NewFakeClientBuilder(dockerConfigJSON, dockercfg, otherRegistry, invalid).Build()
+
+	tests := []struct {
+		name    string
+		secrets []string
+		want    RegistryCredentials
+		wantErr bool
+	}{
+		{"no secrets", nil, RegistryCredentials{}, false},
+		{"missing secret", []string{"missing"}, RegistryCredentials{}, false},
+		{"another registry", []string{"quay"}, RegistryCredentials{}, false},
+		{"dockerconfigjson with auth", []string{"quay", "hub"}, RegistryCredentials{Username: "alice", Password: "s3cr3t:with:colons"}, false},
+		{"dockercfg with username and password", []string{"legacy", "hub"}, RegistryCredentials{Username: "bob", Password: "pass"}, false},
+		{"invalid secret", []string{"invalid"}, RegistryCredentials{}, true},
+	}
+	for _, tt := range tests {
+		var refs []corev1.LocalObjectReference
+		for _, name := range tt.secrets {
+			refs = append(refs, corev1.LocalObjectReference{Name: name})
+		}
+		got, err := GetRegistryCredentials(client, "ns", "docker.io/sonatype/nexus3", refs)
+		if tt.wantErr {
+			assert.Error(t, err, tt.name)
+			continue
+		}
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, got, tt.name)
+	}
+}
+
+func TestSameRegistry(t *testing.T) {
+	assert.True(t, sameRegistry("https://index.docker.io/v1/", communityNexusRegistry))
+	assert.True(t, sameRegistry("docker.io", communityNexusRegistry))
+	assert.True(t, sameRegistry("registry.corp:5000", "https://registry.corp:5000"))
+	assert.False(t, sameRegistry("quay.io", communityNexusRegistry))
+	assert.False(t, sameRegistry("registry.corp", "registry.corp:5000"))
+}
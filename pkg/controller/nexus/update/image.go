@@ -0,0 +1,61 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	"strings"
+
+	"github.com/m88i/nexus-operator/pkg/util"
+)
+
+const (
+	dockerHubDomain       = "docker.io"
+	dockerHubRegistryHost = "registry.hub.docker.com"
+	dockerHubLibrary      = "library"
+)
+
+// ImageRepository returns the image reference without its tag, e.g. "registry.corp:5000/sonatype/nexus3" for "registry.corp:5000/sonatype/nexus3:3.25.0"
+func ImageRepository(image string) string {
+	repository, _ := splitImage(image)
+	return repository
+}
+
+// splitImage separates the image repository from its tag. Registry ports (e.g. "registry.corp:5000/sonatype/nexus3") are not mistaken for tags.
+func splitImage(image string) (repository, tag string) {
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i+1:], "/") {
+		return image, ""
+	}
+	return image[:i], image[i+1:]
+}
+
+// registryAndPath splits an image repository into the registry URL and the repository path within it.
+// References without a registry domain, such as "sonatype/nexus3", are resolved to Docker Hub.
+func registryAndPath(repository string) (registryURL, path string) {
+	domain, path := dockerHubDomain, repository
+	if i := strings.Index(repository, "/"); i >= 0 {
+		candidate := repository[:i]
+		if strings.ContainsAny(candidate, ".:") || candidate == "localhost" {
+			domain, path = candidate, repository[i+1:]
+		}
+	}
+	if dockerHubHosts[domain] {
+		if !strings.Contains(path, "/") {
+			path = dockerHubLibrary + "/" + path
+		}
+		return communityNexusRegistry, path
+	}
+	return util.HTTPSPrefixSchema + domain, path
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitImage(t *testing.T) {
+	tests := []struct {
+		image          string
+		wantRepository string
+		wantTag        string
+	}{
+		{"docker.io/sonatype/nexus3:3.25.0", "docker.io/sonatype/nexus3", "3.25.0"},
+		{"docker.io/sonatype/nexus3", "docker.io/sonatype/nexus3", ""},
+		{"registry.corp:5000/sonatype/nexus3:3.25.0", "registry.corp:5000/sonatype/nexus3", "3.25.0"},
+		{"registry.corp:5000/sonatype/nexus3", "registry.corp:5000/sonatype/nexus3", ""},
+	}
+	for _, tt := range tests {
+		repository, tag := splitImage(tt.image)
+		assert.Equal(t, tt.wantRepository, repository, tt.image)
+		assert.Equal(t, tt.wantTag, tag, tt.image)
+		assert.Equal(t, tt.wantRepository, ImageRepository(tt.image))
+	}
+}
+
+func TestRegistryAndPath(t *testing.T) {
+	tests := []struct {
+		repository   string
+		wantRegistry string
+		wantPath     string
+	}{
+		{"docker.io/sonatype/nexus3", communityNexusRegistry, "sonatype/nexus3"},
+		{"sonatype/nexus3", communityNexusRegistry, "sonatype/nexus3"},
+		{"nginx", communityNexusRegistry, "library/nginx"},
+		{"registry.corp/sonatype/nexus3", "https://registry.corp", "sonatype/nexus3"},
+		{"registry.corp:5000/mirrors/sonatype/nexus3", "https://registry.corp:5000", "mirrors/sonatype/nexus3"},
+		{"localhost/sonatype/nexus3", "https://localhost", "sonatype/nexus3"},
+	}
+	for _, tt := range tests {
+		registryURL, path := registryAndPath(tt.repository)
+		assert.Equal(t, tt.wantRegistry, registryURL, tt.repository)
+		assert.Equal(t, tt.wantPath, path, tt.repository)
+	}
+}
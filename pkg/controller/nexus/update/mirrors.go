@@ -0,0 +1,62 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	"strings"
+
+	"github.com/m88i/nexus-operator/pkg/util"
+)
+
+// ImageMirrorsEnvKey is the Operator environment variable holding the image mirrors, e.g. "docker.io/sonatype/nexus3=registry.corp/sonatype/nexus3"
+const ImageMirrorsEnvKey = "IMAGE_MIRRORS"
+
+// imageMirror replaces the source repository (or a parent path of it) with the mirror repository
+type imageMirror struct {
+	source string
+	mirror string
+}
+
+// getImageMirrors parses the comma separated "source=mirror" pairs set in the Operator environment. Invalid pairs are ignored.
+func getImageMirrors() []imageMirror {
+	var mirrors []imageMirror
+	for _, pair := range strings.Split(util.GetOSEnv(ImageMirrorsEnvKey, ""), ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || len(strings.TrimSpace(parts[0])) == 0 || len(strings.TrimSpace(parts[1])) == 0 {
+			log.Warnf("Invalid image mirror '%s' in the %s environment variable, expected 'source=mirror'. Ignoring it", pair, ImageMirrorsEnvKey)
+			continue
+		}
+		mirrors = append(mirrors, imageMirror{
+			source: strings.TrimSuffix(strings.TrimSpace(parts[0]), "/"),
+			mirror: strings.TrimSuffix(strings.TrimSpace(parts[1]), "/"),
+		})
+	}
+	return mirrors
+}
+
+// MirroredImage rewrites the image to the first mirror whose source matches the image repository or one of its parent paths.
+// The image is returned unchanged if there's no such mirror.
+func MirroredImage(image string) string {
+	for _, m := range getImageMirrors() {
+		if image == m.source || strings.HasPrefix(image, m.source+"/") || strings.HasPrefix(image, m.source+":") || strings.HasPrefix(image, m.source+"@") {
+			return m.mirror + strings.TrimPrefix(image, m.source)
+		}
+	}
+	return image
+}
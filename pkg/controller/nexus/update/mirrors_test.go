@@ -0,0 +1,55 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getImageMirrors(t *testing.T) {
+	_ = os.Setenv(ImageMirrorsEnvKey, " docker.io/sonatype/nexus3=registry.corp/sonatype/nexus3 ,invalid,=registry.corp,registry.connect.redhat.com/=mirror.corp:5000/redhat/,")
+	defer os.Unsetenv(ImageMirrorsEnvKey)
+
+	assert.Equal(t, []imageMirror{
+		{source: "docker.io/sonatype/nexus3", mirror: "registry.corp/sonatype/nexus3"},
+		{source: "registry.connect.redhat.com", mirror: "mirror.corp:5000/redhat"},
+	}, getImageMirrors())
+
+	_ = os.Unsetenv(ImageMirrorsEnvKey)
+	assert.Empty(t, getImageMirrors())
+}
+
+func TestMirroredImage(t *testing.T) {
+	_ = os.Setenv(ImageMirrorsEnvKey, "docker.io/sonatype/nexus3=registry.corp/sonatype/nexus3,registry.connect.redhat.com=mirror.corp:5000/redhat")
+	defer os.Unsetenv(ImageMirrorsEnvKey)
+
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"docker.io/sonatype/nexus3", "registry.corp/sonatype/nexus3"},
+		{"docker.io/sonatype/nexus3:3.25.0", "registry.corp/sonatype/nexus3:3.25.0"},
+		{"docker.io/sonatype/nexus3@sha256:abc", "registry.corp/sonatype/nexus3@sha256:abc"},
+		{"registry.connect.redhat.com/sonatype/nexus-repository-manager", "mirror.corp:5000/redhat/sonatype/nexus-repository-manager"},
+		{"docker.io/sonatype/nexus3-custom", "docker.io/sonatype/nexus3-custom"},
+		{"quay.io/some/image:1.0", "quay.io/some/image:1.0"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MirroredImage(tt.image), tt.image)
+	}
+}
@@ -18,6 +18,7 @@ package update
 import (
 	ctx "context"
 	"fmt"
+	"github.com/RHsyseng/operator-utils/pkg/resource"
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,13 +40,23 @@ const (
 //   - mark an update as started
 //   - mark an update as finished
 // If an update fails automatic updates are disabled and the image is set to the previously deployed tag
+// The deployed and required workloads are either both Deployments or both StatefulSets
 //
 // This is a state machine with two states: "idle" and "updating".
 // "idle" transitions into "updating" if isNewUpdate == true.
 // "updating" transitions back to "idle" if automatic updates get disabled or if the update fails/succeeds.
 // "updating" transitions to itself if isNewUpdate == true.
-func HandleUpdate(nexus *v1alpha1.Nexus, deployed, required *appsv1.Deployment, scheme *runtime.Scheme, c client.Client) error {
-	if nexus.Spec.AutomaticUpdate.Disabled || differentImagesOrMinors(deployed, required) {
+func HandleUpdate(nexus *v1alpha1.Nexus, deployed, required resource.KubernetesResource, scheme *runtime.Scheme, c client.Client) error {
+	deployedImage, err := serverImage(deployed)
+	if err != nil {
+		return err
+	}
+	requiredImage, err := serverImage(required)
+	if err != nil {
+		return err
+	}
+
+	if nexus.Spec.AutomaticUpdate.Disabled || differentImagesOrMinors(deployedImage, requiredImage) {
 		if alreadyUpdating(nexus) {
 			// we were in an update, so let's clear its status
 			nexus.Status.UpdateConditions = nil
@@ -56,7 +67,7 @@ func HandleUpdate(nexus *v1alpha1.Nexus, deployed, required *appsv1.Deployment,
 	// it's important to check if this is a new update before checking ongoing updates because
 	// if this is a new update, the one that was happening before no longer matters
 	// so we just reset the update state and return
-	if newUpdate, previousTag, targetTag := isNewUpdate(deployed, required); newUpdate {
+	if newUpdate, previousTag, targetTag := isNewUpdate(deployedImage, requiredImage); newUpdate {
 		log.Infof("Update from %s to %s started", previousTag, targetTag)
 		// the Nexus status update can be delayed, let's leave it to the reconciler
 		nexus.Status.UpdateConditions = []string{fmt.Sprintf(updateStartFormat, previousTag, targetTag)}
@@ -71,35 +82,40 @@ func HandleUpdate(nexus *v1alpha1.Nexus, deployed, required *appsv1.Deployment,
 
 	previousTag, targetTag, err := getUpdateTags(nexus)
 	if err != nil {
-		log.Warnf("Failed to parse 'status.updateConditions' from %s: %v. Was it tampered with? Unable to monitor ongoing update, human intervention may be required.", deployed.Name, err)
+		log.Warnf("Failed to parse 'status.updateConditions' from %s: %v. Was it tampered with? Unable to monitor ongoing update, human intervention may be required.", deployed.GetName(), err)
 		nexus.Status.UpdateConditions = nil
 		return nil
 	}
 
-	for _, condition := range deployed.Status.Conditions {
-		if condition.Type == appsv1.DeploymentProgressing && condition.Status == "False" {
-			log.Errorf("Update to %s failed: %s. %s. Human intervention may be required", targetTag, condition.Reason, condition.Message)
-			nexus.Status.UpdateConditions = append(nexus.Status.UpdateConditions, fmt.Sprintf(updateFailedFormat, previousTag, targetTag))
-
-			// we must return an error if we can't disable automatic updates
-			// this can't be delayed like the status updates as need the reconcile request to be requeued
-			if err := rollback(nexus, previousTag, c); err != nil {
-				return fmt.Errorf("the update has failed, but could not disable automatic updates: %v", err)
+	state, reason, err := rolloutStatus(deployed, c)
+	if err != nil {
+		return err
+	}
+	switch state {
+	case rolloutFailed:
+		log.Errorf("Update to %s failed: %s. Human intervention may be required", targetTag, reason)
+		nexus.Status.UpdateConditions = append(nexus.Status.UpdateConditions, fmt.Sprintf(updateFailedFormat, previousTag, targetTag))
+
+		// we must return an error if we can't disable automatic updates
+		// this can't be delayed like the status updates as need the reconcile request to be requeued
+		if err := rollback(nexus, previousTag, c); err != nil {
+			return fmt.Errorf("the update has failed, but could not disable automatic updates: %v", err)
+		}
+		if statefulSet, ok := deployed.(*appsv1.StatefulSet); ok {
+			if err := rollbackStatefulSet(statefulSet, MirroredImage(nexus.Spec.Image), c); err != nil {
+				return fmt.Errorf("the update has failed, but could not roll the StatefulSet back: %v", err)
 			}
-
-			// we don't want to create spurious events, so we only raise it after we've disabled updates
-			// and we know this part of the function won't be reached again
-			createUpdateFailureEvent(nexus, scheme, c, targetTag)
-			return nil
 		}
 
-		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "NewReplicaSetAvailable" {
-			log.Infof("Successfully updated to %s", targetTag)
-			// the Nexus status update can be delayed, let's leave it to the reconciler
-			nexus.Status.UpdateConditions = append(nexus.Status.UpdateConditions, fmt.Sprintf(updateOKFormat, previousTag, targetTag))
-			createUpdateSuccessEvent(nexus, scheme, c, targetTag)
-			return nil
-		}
+		// we don't want to create spurious events, so we only raise it after we've disabled updates
+		// and we know this part of the function won't be reached again
+		createUpdateFailureEvent(nexus, scheme, c, targetTag)
+
+	case rolloutComplete:
+		log.Infof("Successfully updated to %s", targetTag)
+		// the Nexus status update can be delayed, let's leave it to the reconciler
+		nexus.Status.UpdateConditions = append(nexus.Status.UpdateConditions, fmt.Sprintf(updateOKFormat, previousTag, targetTag))
+		createUpdateSuccessEvent(nexus, scheme, c, targetTag)
 	}
 	return nil
 }
@@ -115,19 +131,17 @@ func alreadyUpdating(nexus *v1alpha1.Nexus) bool {
 	return true
 }
 
-func isNewUpdate(deployed, required *appsv1.Deployment) (updating bool, previousTag, targetTag string) {
-	depImage := deployed.Spec.Template.Spec.Containers[0].Image
-	reqImage := required.Spec.Template.Spec.Containers[0].Image
-	deployedImageParts := strings.Split(depImage, ":")
-	requiredImageParts := strings.Split(reqImage, ":")
+func isNewUpdate(deployedImage, requiredImage string) (updating bool, previousTag, targetTag string) {
+	_, depTag := splitImage(deployedImage)
+	_, reqTag := splitImage(requiredImage)
 
-	updating, err := HigherVersion(requiredImageParts[1], deployedImageParts[1])
+	updating, err := HigherVersion(reqTag, depTag)
 	if err != nil {
-		log.Warnf("Unable to check if the required deployment (%s) is an update when comparing to the deployed one: %v", required.Name, err)
+		log.Warnf("Unable to check if the required image (%s) is an update when comparing to the deployed one (%s): %v", requiredImage, deployedImage, err)
 		return
 	}
-	previousTag = deployedImageParts[1]
-	targetTag = requiredImageParts[1]
+	previousTag = depTag
+	targetTag = reqTag
 	return
 }
 
@@ -136,28 +150,26 @@ func getUpdateTags(nexus *v1alpha1.Nexus) (previousTag, targetTag string, err er
 	return
 }
 
-func differentImagesOrMinors(deployed, required *appsv1.Deployment) bool {
-	depImage := deployed.Spec.Template.Spec.Containers[0].Image
-	reqImage := required.Spec.Template.Spec.Containers[0].Image
-	requiredImageParts := strings.Split(reqImage, ":")
-	deployedImageParts := strings.Split(depImage, ":")
+func differentImagesOrMinors(deployedImage, requiredImage string) bool {
+	depRepository, depTag := splitImage(deployedImage)
+	reqRepository, reqTag := splitImage(requiredImage)
 
 	// different images, not an update
-	if requiredImageParts[0] != deployedImageParts[0] {
+	if reqRepository != depRepository {
 		return true
 	}
 
 	// Might be the same, but we can't tell, so let's be conservative and say it isn't
-	if len(deployedImageParts) == 1 || deployedImageParts[1] == "latest" {
+	if len(depTag) == 0 || depTag == "latest" {
 		return true
 	}
 
 	// we should be able to assume there will be no parsing error, we just created this deployment in the reconcile loop
-	reqMinor, _ := getMinor(requiredImageParts[1])
+	reqMinor, _ := getMinor(reqTag)
 	// the deployed one, on the other hand, might have been tampered with
-	depMinor, err := getMinor(deployedImageParts[1])
+	depMinor, err := getMinor(depTag)
 	if err != nil {
-		log.Warnf("Unable to parse the deployed Deployment's (%s) tag: %v. Cannot determine if this is an update. Has it been tampered with?", deployed.Name, err)
+		log.Warnf("Unable to parse the deployed image's (%s) tag: %v. Cannot determine if this is an update. Has it been tampered with?", deployedImage, err)
 		return true
 	}
 
@@ -169,6 +181,6 @@ func rollback(nexus *v1alpha1.Nexus, tag string, c client.Client) error {
 	nexus.Spec.AutomaticUpdate.Disabled = true
 	nexus.Spec.AutomaticUpdate.MinorVersion = nil
 	// Let's set the tag to one we know is working.
-	nexus.Spec.Image = fmt.Sprintf("%s:%s", strings.Split(nexus.Spec.Image, ":")[0], tag)
+	nexus.Spec.Image = fmt.Sprintf("%s:%s", ImageRepository(nexus.Spec.Image), tag)
 	return c.Update(ctx.Background(), nexus)
 }
@@ -15,12 +15,15 @@
 package update
 
 import (
+	ctx "context"
 	"fmt"
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
+	"github.com/m88i/nexus-operator/pkg/framework"
 	"github.com/m88i/nexus-operator/pkg/test"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"testing"
 )
@@ -136,6 +139,97 @@ func TestMonitorUpdate(t *testing.T) {
 	assert.Nil(t, nexus.Status.UpdateConditions)
 }
 
+func TestMonitorUpdate_statefulSet(t *testing.T) {
+	image := "image"
+	replicas := int32(1)
+	labels := map[string]string{"app": "nexus"}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus", Namespace: "test"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{Image: fmt.Sprintf("%s:%s", image, "3.25.1")}},
+					Containers:     []corev1.Container{{Image: fmt.Sprintf("%s:%s", image, "3.25.1")}},
+				},
+			},
+		},
+		Status: appsv1.StatefulSetStatus{CurrentRevision: "nexus-1", UpdateRevision: "nexus-2"},
+	}
+	stuckPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nexus-0",
+			Namespace: "test",
+			Labels:    map[string]string{"app": "nexus", appsv1.StatefulSetRevisionLabel: "nexus-2"},
+		},
+	}
+	nexus := &v1alpha1.Nexus{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus", Namespace: "test"},
+		Status:     v1alpha1.NexusStatus{UpdateConditions: []string{fmt.Sprintf(updateStartFormat, "3.25.0", "3.25.1")}},
+		Spec:       v1alpha1.NexusSpec{Image: fmt.Sprintf("%s:%s", image, "3.25.1")},
+	}
+	c := test.NewFakeClientBuilder(nexus, statefulSet, stuckPod).Build()
+	required := statefulSet.DeepCopy()
+
+	// In an update and it's still progressing
+	err := HandleUpdate(nexus, statefulSet, required, c.Scheme(), c)
+	assert.Nil(t, err)
+	assert.Len(t, nexus.Status.UpdateConditions, 1)
+
+	// In an update and it fails because the pod from the new revision can't start
+	stuckPod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+	}}
+	assert.NoError(t, c.Update(ctx.TODO(), stuckPod))
+
+	err = HandleUpdate(nexus, statefulSet, required, c.Scheme(), c)
+	assert.Nil(t, err)
+	assert.Len(t, nexus.Status.UpdateConditions, 2)
+	assert.Equal(t, fmt.Sprintf(updateFailedFormat, "3.25.0", "3.25.1"), nexus.Status.UpdateConditions[1])
+	assert.True(t, nexus.Spec.AutomaticUpdate.Disabled)
+	assert.Equal(t, fmt.Sprintf("%s:%s", image, "3.25.0"), nexus.Spec.Image)
+	assert.True(t, test.EventExists(c, failedUpdateReason))
+	// the StatefulSet is reverted and the stuck pod removed, so it can be recreated
+	reverted := &appsv1.StatefulSet{}
+	assert.NoError(t, c.Get(ctx.TODO(), framework.Key(statefulSet), reverted))
+	assert.Equal(t, nexus.Spec.Image, reverted.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, nexus.Spec.Image, reverted.Spec.Template.Spec.InitContainers[0].Image)
+	assert.True(t, errors.IsNotFound(c.Get(ctx.TODO(), framework.Key(stuckPod), &corev1.Pod{})))
+
+	// In an update and it succeeds
+	nexus.Spec.AutomaticUpdate.Disabled = false
+	nexus.Status.UpdateConditions = []string{fmt.Sprintf(updateStartFormat, "3.25.0", "3.25.1")}
+	statefulSet.Status = appsv1.StatefulSetStatus{
+		CurrentRevision: "nexus-2",
+		UpdateRevision:  "nexus-2",
+		UpdatedReplicas: 1,
+		ReadyReplicas:   1,
+	}
+
+	err = HandleUpdate(nexus, statefulSet, required, c.Scheme(), c)
+	assert.Nil(t, err)
+	assert.Len(t, nexus.Status.UpdateConditions, 2)
+	assert.Equal(t, fmt.Sprintf(updateOKFormat, "3.25.0", "3.25.1"), nexus.Status.UpdateConditions[1])
+	assert.True(t, test.EventExists(c, successfulUpdateReason))
+}
+
+func TestMonitorUpdate_unsupportedWorkload(t *testing.T) {
+	nexus := &v1alpha1.Nexus{Spec: v1alpha1.NexusSpec{Image: "image:3.25.1"}}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus", Namespace: "test"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Image: nexus.Spec.Image}}}},
+		},
+	}
+	client := test.NewFakeClientBuilder(nexus).Build()
+
+	err := HandleUpdate(nexus, &corev1.Service{}, deployment, client.Scheme(), client)
+	assert.EqualError(t, err, "unsupported workload type *v1.Service")
+	err = HandleUpdate(nexus, deployment, &appsv1.Deployment{}, client.Scheme(), client)
+	assert.Error(t, err)
+}
+
 func Test_alreadyUpdating(t *testing.T) {
 	// We already tested most behaviors in TestMonitorUpdate
 	// There was an update, but it's done now
@@ -149,54 +243,24 @@ func Test_alreadyUpdating(t *testing.T) {
 func Test_isNewUpdate(t *testing.T) {
 	// We already tested most behaviors in TestMonitorUpdate
 	image := "image"
-	baseDeployment := &appsv1.Deployment{
-		Spec: appsv1.DeploymentSpec{
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{{}},
-				},
-			},
-		},
-	}
-
-	deployedDep := baseDeployment.DeepCopy()
-	requiredDep := baseDeployment.DeepCopy()
 
 	// invalid tag
-	deployedDep.Spec.Template.Spec.Containers[0].Image = fmt.Sprintf("%s:%s", image, "3.25.0")
-	requiredDep.Spec.Template.Spec.Containers[0].Image = fmt.Sprintf("%s:%s", image, "3..0")
-	updating, _, _ := isNewUpdate(deployedDep, requiredDep)
+	updating, _, _ := isNewUpdate(fmt.Sprintf("%s:%s", image, "3.25.0"), fmt.Sprintf("%s:%s", image, "3..0"))
 	assert.False(t, updating)
 }
 
 func Test_differentImagesOrMinors(t *testing.T) {
 	image := "image"
-	baseDeployment := &appsv1.Deployment{
-		Spec: appsv1.DeploymentSpec{
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{{}},
-				},
-			},
-		},
-	}
-
-	deployedDep := baseDeployment.DeepCopy()
-	requiredDep := baseDeployment.DeepCopy()
 
 	// different images
-	deployedDep.Spec.Template.Spec.Containers[0].Image = image
-	assert.True(t, differentImagesOrMinors(deployedDep, requiredDep))
+	assert.True(t, differentImagesOrMinors(image, ""))
 
 	// deployed using no tag (same as 'latest')
-	requiredDep.Spec.Template.Spec.Containers[0].Image = fmt.Sprintf("%s:%s", image, "3.25.0")
-	assert.True(t, differentImagesOrMinors(deployedDep, requiredDep))
+	assert.True(t, differentImagesOrMinors(image, fmt.Sprintf("%s:%s", image, "3.25.0")))
 
 	// invalid deployed tag
-	deployedDep.Spec.Template.Spec.Containers[0].Image = fmt.Sprintf("%s:%s", image, "3..0")
-	assert.True(t, differentImagesOrMinors(deployedDep, requiredDep))
+	assert.True(t, differentImagesOrMinors(fmt.Sprintf("%s:%s", image, "3..0"), fmt.Sprintf("%s:%s", image, "3.25.0")))
 
 	// same minor
-	deployedDep.Spec.Template.Spec.Containers[0].Image = fmt.Sprintf("%s:%s", image, "3.25.0")
-	assert.False(t, differentImagesOrMinors(deployedDep, requiredDep))
+	assert.False(t, differentImagesOrMinors(fmt.Sprintf("%s:%s", image, "3.25.0"), fmt.Sprintf("%s:%s", image, "3.25.0")))
 }
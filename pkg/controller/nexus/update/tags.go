@@ -18,14 +18,15 @@ import (
 	"fmt"
 	"github.com/heroku/docker-registry-client/registry"
 	"github.com/m88i/nexus-operator/pkg/logger"
+	"github.com/m88i/nexus-operator/pkg/util"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
-	communityNexusRegistry     = "https://registry.hub.docker.com"
-	communityNexusRepo         = "sonatype/nexus3"
+	communityNexusRegistry     = util.HTTPSPrefixSchema + dockerHubRegistryHost
 	tagParseFailureFormat      = "unable to parse tag \"%s\": %v"
 	unableToCheckUpdatesFormat = "Unable to check for updates: %v"
 )
@@ -36,11 +37,36 @@ const (
 )
 
 var (
+	// tags fetched from each image repository with each set of credentials, so the tags of a private repository
+	// are only served to the instances able to read them. Reconciles may run concurrently, guard it with cachesMutex.
+	caches      = make(map[cacheKey]*tagCache)
+	cachesMutex sync.Mutex
+	log         = logger.GetLogger("update")
+)
+
+// cacheKey identifies the tags fetched from an image repository (e.g. "docker.io/sonatype/nexus3") with the given credentials
+type cacheKey struct {
+	repository  string
+	credentials RegistryCredentials
+}
+
+// tagCache holds the most recent tags of each minor for a single image repository
+type tagCache struct {
 	lastQuery    time.Time
 	lastErr      time.Time
-	latestMicros = make(map[int]string)
-	log          = logger.GetLogger("update")
-)
+	latestMicros map[int]string
+}
+
+// getCache must be called with cachesMutex held
+func getCache(repository string, credentials RegistryCredentials) *tagCache {
+	key := cacheKey{repository: repository, credentials: credentials}
+	cache, ok := caches[key]
+	if !ok {
+		cache = &tagCache{latestMicros: make(map[int]string)}
+		caches[key] = cache
+	}
+	return cache
+}
 
 // HigherVersion checks if thisTag is of a higher version than otherTag
 func HigherVersion(thisTag, otherTag string) (bool, error) {
@@ -67,28 +93,36 @@ func HigherVersion(thisTag, otherTag string) (bool, error) {
 	return thisMicro > otherMicro, nil
 }
 
-// GetLatestMicro returns the most recent image tag within a minor (the "y" in "x.y.z").
+// GetLatestMicro returns the most recent tag of the image repository (e.g. "docker.io/sonatype/nexus3") within a minor (the "y" in "x.y.z").
 // If the minor was not found or if we never managed to fetch any tags, the second return value is false.
-func GetLatestMicro(minor int) (tag string, ok bool) {
-	if time.Since(lastQuery) > ttl {
-		fetchUpdates()
-	}
-	tag, ok = latestMicros[minor]
+// The credentials are only used if the tags have to be fetched from the registry.
+func GetLatestMicro(repository string, minor int, credentials RegistryCredentials) (tag string, ok bool) {
+	cachesMutex.Lock()
+	defer cachesMutex.Unlock()
+	cache := getCache(repository, credentials)
+	if time.Since(cache.lastQuery) > ttl {
+		cache.fetchUpdates(repository, credentials)
+	}
+	tag, ok = cache.latestMicros[minor]
 	return
 }
 
-// GetLatestMinor returns the most recent minor (the "y" in "x.y.z").
+// GetLatestMinor returns the most recent minor (the "y" in "x.y.z") of the image repository (e.g. "docker.io/sonatype/nexus3").
 // If there were issues fetching the tags it returns an error.
-func GetLatestMinor() (int, error) {
-	if time.Since(lastQuery) > ttl {
-		fetchUpdates()
-	}
-	if len(latestMicros) == 0 {
+// The credentials are only used if the tags have to be fetched from the registry.
+func GetLatestMinor(repository string, credentials RegistryCredentials) (int, error) {
+	cachesMutex.Lock()
+	defer cachesMutex.Unlock()
+	cache := getCache(repository, credentials)
+	if time.Since(cache.lastQuery) > ttl {
+		cache.fetchUpdates(repository, credentials)
+	}
+	if len(cache.latestMicros) == 0 {
 		return 0, fmt.Errorf("unable to fetch tags")
 	}
 
 	greatestMinor := 0
-	for minor := range latestMicros {
+	for minor := range cache.latestMicros {
 		if minor > greatestMinor {
 			greatestMinor = minor
 		}
@@ -96,28 +130,29 @@ func GetLatestMinor() (int, error) {
 	return greatestMinor, nil
 }
 
-func fetchUpdates() {
-	if time.Since(lastErr) < errTTL {
+func (c *tagCache) fetchUpdates(repository string, credentials RegistryCredentials) {
+	if time.Since(c.lastErr) < errTTL {
 		log.Debugf("Trying to fetch tags from registry again too fast, must try again later")
 		return
 	}
 
-	tags, err := getTags()
+	tags, err := getTags(repository, credentials)
 	if err != nil {
-		lastErr = time.Now()
+		c.lastErr = time.Now()
 		log.Errorf(unableToCheckUpdatesFormat, err)
 		return
 	}
-	lastQuery = time.Now()
+	c.lastQuery = time.Now()
 
-	if err = parseTagsAndUpdate(tags); err != nil {
+	if err = c.parseTagsAndUpdate(tags); err != nil {
 		log.Errorf(unableToCheckUpdatesFormat, err)
 		return
 	}
 }
 
-func getTags() ([]string, error) {
-	reg, err := registry.New(communityNexusRegistry, "", "")
+func getTags(repository string, credentials RegistryCredentials) ([]string, error) {
+	registryURL, repo := registryAndPath(repository)
+	reg, err := registry.New(registryURL, credentials.Username, credentials.Password)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create client for registry: %v", err)
 	}
@@ -127,7 +162,6 @@ func getTags() ([]string, error) {
 		log.Infof(format, args)
 	}
 
-	repo := communityNexusRepo
 	tags, err := reg.Tags(repo)
 	if err != nil {
 		return nil, fmt.Errorf("unable to fetch tags from %s: %v", repo, err)
@@ -135,7 +169,7 @@ func getTags() ([]string, error) {
 	return tags, nil
 }
 
-func parseTagsAndUpdate(tags []string) error {
+func (c *tagCache) parseTagsAndUpdate(tags []string) error {
 	for _, candidateTag := range tags {
 		if candidateTag != "latest" {
 			candidateMinor, err := getMinor(candidateTag)
@@ -146,15 +180,15 @@ func parseTagsAndUpdate(tags []string) error {
 			if err != nil {
 				return fmt.Errorf(tagParseFailureFormat, candidateTag, err)
 			}
-			storedTag, ok := latestMicros[candidateMinor]
+			storedTag, ok := c.latestMicros[candidateMinor]
 			if ok {
 				// we can safely ignore the error. It wouldn't be stored if it was invalid
 				storedMicro, _ := getMicro(storedTag)
 				if candidateMicro > storedMicro {
-					latestMicros[candidateMinor] = candidateTag
+					c.latestMicros[candidateMinor] = candidateTag
 				}
 			} else {
-				latestMicros[candidateMinor] = candidateTag
+				c.latestMicros[candidateMinor] = candidateTag
 			}
 		}
 	}
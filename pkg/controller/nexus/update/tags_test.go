@@ -105,45 +105,53 @@ func TestHigherVersion(t *testing.T) {
 }
 
 func TestGetLatestMicro(t *testing.T) {
-	latestMicros = make(map[int]string)
-	lastQuery = time.Now()
+	repository := t.Name()
+	caches[cacheKey{repository: repository}] = &tagCache{lastQuery: time.Now(), latestMicros: make(map[int]string)}
 	minor := 0
-	latestMicros[minor] = "3.0.0"
-	_, ok := GetLatestMicro(minor)
+	caches[cacheKey{repository: repository}].latestMicros[minor] = "3.0.0"
+	_, ok := GetLatestMicro(repository, minor, RegistryCredentials{})
 	assert.True(t, ok)
-	_, ok = GetLatestMicro(1)
+	_, ok = GetLatestMicro(repository, 1, RegistryCredentials{})
+	assert.False(t, ok)
+	// tags from one repository are not mixed with another's
+	caches[cacheKey{repository: "mirror"}] = &tagCache{lastQuery: time.Now(), latestMicros: make(map[int]string)}
+	_, ok = GetLatestMicro("mirror", minor, RegistryCredentials{})
+	assert.False(t, ok)
+	// nor served to instances using other credentials
+	credentials := RegistryCredentials{Username: "user", Password: "secret"}
+	caches[cacheKey{repository: repository, credentials: credentials}] = &tagCache{lastQuery: time.Now(), latestMicros: make(map[int]string)}
+	_, ok = GetLatestMicro(repository, minor, credentials)
 	assert.False(t, ok)
 }
 
 func TestGetLatestMinor(t *testing.T) {
-	latestMicros = make(map[int]string)
-	lastQuery = time.Now()
+	repository := t.Name()
+	caches[cacheKey{repository: repository}] = &tagCache{lastQuery: time.Now(), latestMicros: make(map[int]string)}
 	lowerMinor := 0
 	higherMinor := 1
 	// first, let's test the scenario where we couldn't fetch tags
-	_, err := GetLatestMinor()
+	_, err := GetLatestMinor(repository, RegistryCredentials{})
 	assert.NotNil(t, err)
 	// now let's populate the tags and test
-	latestMicros[lowerMinor] = ""
-	latestMicros[higherMinor] = ""
-	minor, err := GetLatestMinor()
+	caches[cacheKey{repository: repository}].latestMicros[lowerMinor] = ""
+	caches[cacheKey{repository: repository}].latestMicros[higherMinor] = ""
+	minor, err := GetLatestMinor(repository, RegistryCredentials{})
 	assert.Nil(t, err)
 	assert.Equal(t, higherMinor, minor)
 }
 
 func TestParseTagsAndUpdate(t *testing.T) {
-	// make sure latestMicros is blank
-	latestMicros = make(map[int]string)
+	cache := getCache(t.Name(), RegistryCredentials{})
 	validTags := []string{"latest", "3.0.0", "3.0.1", "3.1.0"}
-	assert.NoError(t, parseTagsAndUpdate(validTags))
-	assert.Len(t, latestMicros, 2)
-	assert.Equal(t, "3.0.1", latestMicros[0])
-	assert.Equal(t, "3.1.0", latestMicros[1])
+	assert.NoError(t, cache.parseTagsAndUpdate(validTags))
+	assert.Len(t, cache.latestMicros, 2)
+	assert.Equal(t, "3.0.1", cache.latestMicros[0])
+	assert.Equal(t, "3.1.0", cache.latestMicros[1])
 
 	invalidMinor := []string{"3..0"}
 	invalidMicro := []string{"3.25."}
-	assert.Error(t, parseTagsAndUpdate(invalidMinor))
-	assert.Error(t, parseTagsAndUpdate(invalidMicro))
+	assert.Error(t, cache.parseTagsAndUpdate(invalidMinor))
+	assert.Error(t, cache.parseTagsAndUpdate(invalidMicro))
 }
 
 func TestGetMinor(t *testing.T) {
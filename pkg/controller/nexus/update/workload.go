@@ -0,0 +1,178 @@
+// Copyright 2020 Nexus Operator and/or its authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	ctx "context"
+	"fmt"
+
+	"github.com/RHsyseng/operator-utils/pkg/resource"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rolloutState is the outcome of the rollout of a workload's latest pod template
+type rolloutState int
+
+const (
+	rolloutProgressing rolloutState = iota
+	rolloutComplete
+	rolloutFailed
+)
+
+// failedPodReasons are the container waiting reasons which will never get better on their own
+var failedPodReasons = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CrashLoopBackOff":           true,
+	"CreateContainerConfigError": true,
+}
+
+// podTemplate returns the pod template of the workload running Nexus, either a Deployment or a StatefulSet
+func podTemplate(workload resource.KubernetesResource) (*corev1.PodTemplateSpec, error) {
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		return &w.Spec.Template, nil
+	case *appsv1.StatefulSet:
+		return &w.Spec.Template, nil
+	}
+	return nil, fmt.Errorf("unsupported workload type %T", workload)
+}
+
+// serverImage returns the image of the Nexus server container
+func serverImage(workload resource.KubernetesResource) (string, error) {
+	template, err := podTemplate(workload)
+	if err != nil {
+		return "", err
+	}
+	if len(template.Spec.Containers) == 0 {
+		return "", fmt.Errorf("the workload %s has no containers", workload.GetName())
+	}
+	return template.Spec.Containers[0].Image, nil
+}
+
+// rolloutStatus checks how the rollout of the deployed workload is going. The reason is only informed for failures.
+func rolloutStatus(workload resource.KubernetesResource, c client.Client) (state rolloutState, reason string, err error) {
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		state, reason = deploymentRolloutStatus(w)
+		return
+	case *appsv1.StatefulSet:
+		return statefulSetRolloutStatus(w, c)
+	}
+	return rolloutProgressing, "", fmt.Errorf("unsupported workload type %T", workload)
+}
+
+func deploymentRolloutStatus(deployment *appsv1.Deployment) (rolloutState, string) {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == "False" {
+			return rolloutFailed, fmt.Sprintf("%s. %s", condition.Reason, condition.Message)
+		}
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "NewReplicaSetAvailable" {
+			return rolloutComplete, ""
+		}
+	}
+	return rolloutProgressing, ""
+}
+
+// statefulSetRolloutStatus considers the rollout complete once every replica runs the update revision and is ready.
+// StatefulSets have no progress deadline, so the rollout is considered failed as soon as a pod from the update revision
+// is stuck in a state it won't recover from.
+func statefulSetRolloutStatus(statefulSet *appsv1.StatefulSet, c client.Client) (rolloutState, string, error) {
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	if statefulSet.Status.ObservedGeneration >= statefulSet.Generation &&
+		statefulSet.Status.UpdateRevision == statefulSet.Status.CurrentRevision &&
+		statefulSet.Status.UpdatedReplicas == replicas &&
+		statefulSet.Status.ReadyReplicas == replicas {
+		return rolloutComplete, "", nil
+	}
+
+	pods, err := updateRevisionPods(statefulSet, c)
+	if err != nil {
+		return rolloutProgressing, "", err
+	}
+	for _, pod := range pods {
+		if reason := failedPodReason(&pod); len(reason) > 0 {
+			return rolloutFailed, fmt.Sprintf("Pod %s is stuck in %s", pod.Name, reason), nil
+		}
+	}
+	return rolloutProgressing, "", nil
+}
+
+// updateRevisionPods lists the StatefulSet's pods created from its update revision
+func updateRevisionPods(statefulSet *appsv1.StatefulSet, c client.Client) ([]corev1.Pod, error) {
+	if len(statefulSet.Status.UpdateRevision) == 0 {
+		return nil, nil
+	}
+	labels := client.MatchingLabels{appsv1.StatefulSetRevisionLabel: statefulSet.Status.UpdateRevision}
+	for key, value := range statefulSet.Spec.Selector.MatchLabels {
+		labels[key] = value
+	}
+	pods := &corev1.PodList{}
+	if err := c.List(ctx.TODO(), pods, client.InNamespace(statefulSet.Namespace), labels); err != nil {
+		return nil, fmt.Errorf("could not list the pods from the StatefulSet %s update revision: %v", statefulSet.Name, err)
+	}
+	return pods.Items, nil
+}
+
+func failedPodReason(pod *corev1.Pod) string {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.State.Waiting != nil && failedPodReasons[status.State.Waiting.Reason] {
+			return status.State.Waiting.Reason
+		}
+	}
+	return ""
+}
+
+// rollbackStatefulSet reverts the StatefulSet to the given image and deletes the pods stuck on the failed update revision.
+// Unlike Deployments, StatefulSets won't replace a broken pod on their own after the template is reverted.
+func rollbackStatefulSet(statefulSet *appsv1.StatefulSet, image string, c client.Client) error {
+	pods, err := updateRevisionPods(statefulSet, c)
+	if err != nil {
+		return err
+	}
+
+	failedImage, err := serverImage(statefulSet)
+	if err != nil {
+		return err
+	}
+	statefulSet = statefulSet.DeepCopy()
+	for i := range statefulSet.Spec.Template.Spec.InitContainers {
+		if statefulSet.Spec.Template.Spec.InitContainers[i].Image == failedImage {
+			statefulSet.Spec.Template.Spec.InitContainers[i].Image = image
+		}
+	}
+	for i := range statefulSet.Spec.Template.Spec.Containers {
+		if statefulSet.Spec.Template.Spec.Containers[i].Image == failedImage {
+			statefulSet.Spec.Template.Spec.Containers[i].Image = image
+		}
+	}
+	if err := c.Update(ctx.TODO(), statefulSet); err != nil {
+		return fmt.Errorf("could not revert the StatefulSet %s to %s: %v", statefulSet.Name, image, err)
+	}
+
+	for i := range pods {
+		if err := c.Delete(ctx.TODO(), &pods[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("could not delete the pod %s from the failed update: %v", pods[i].Name, err)
+		}
+	}
+	return nil
+}
@@ -36,6 +36,9 @@ type WatchedObjects struct {
 	Objects []runtime.Object
 	// Owner of the object if different from the actual controller
 	Owner runtime.Object
+	// Handler enqueues the requests when the objects change, for objects not owned by the controller.
+	// If set, Owner is ignored.
+	Handler handler.EventHandler
 }
 
 // ControllerWatcher helps to add required objects to the controller watch list given the required runtime objects
@@ -114,7 +117,11 @@ func (c *controllerWatcher) Watch(watchedObjects ...WatchedObjects) (err error)
 	ownerHandler := &handler.EnqueueRequestForOwner{IsController: true, OwnerType: c.owner}
 	for _, desiredObject := range desiredObjects {
 		for _, runtimeObj := range desiredObject.Objects {
-			if desiredObject.Owner == nil {
+			if desiredObject.Handler != nil {
+				if err = c.controller.Watch(&source.Kind{Type: runtimeObj}, desiredObject.Handler); err != nil {
+					return
+				}
+			} else if desiredObject.Owner == nil {
 				if err = c.controller.Watch(&source.Kind{Type: runtimeObj}, ownerHandler); err != nil {
 					return
 				}
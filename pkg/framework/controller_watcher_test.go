@@ -26,6 +26,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 var requiredObjects = []WatchedObjects{
@@ -92,3 +94,18 @@ func Test_controllerWatcher_WatchWithRouteOnOpenShift(t *testing.T) {
 	// we should only have the objects from Kubernetes core and Route (route/v1)
 	assert.Len(t, controller.GetWatchedSources(), 5)
 }
+
+func Test_controllerWatcher_WatchWithCustomHandler(t *testing.T) {
+	cli := test.NewFakeClientBuilder().Build()
+	controller := test.NewController()
+	manager := test.NewManager(cli)
+
+	watcher := NewControllerWatcher(cli, manager, controller, &v1alpha1.Nexus{})
+	err := watcher.Watch(WatchedObjects{
+		Objects: []runtime.Object{&corev1.ConfigMap{}, &corev1.Secret{}},
+		Handler: &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(func(handler.MapObject) []reconcile.Request { return nil })},
+	})
+	assert.NoError(t, err)
+	assert.True(t, watcher.AreAllObjectsWatched())
+	assert.Len(t, controller.GetWatchedSources(), 2)
+}
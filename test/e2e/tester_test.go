@@ -7,7 +7,7 @@ import (
 
 	"github.com/m88i/nexus-operator/pkg/apis/apps/v1alpha1"
 	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/deployment"
-	"github.com/m88i/nexus-operator/pkg/controller/nexus/server"
+	"github.com/m88i/nexus-operator/pkg/controller/nexus/resource/meta"
 	"github.com/m88i/nexus-operator/pkg/framework"
 	"github.com/operator-framework/operator-sdk/pkg/test"
 	"github.com/operator-framework/operator-sdk/pkg/test/e2eutil"
@@ -224,7 +224,7 @@ func (tester *tester) checkServerInteraction(nexus *v1alpha1.Nexus) {
 			}
 			return false, err
 		}
-		if len(secret.Data[server.SecretKeyUsername]) > 0 && len(secret.Data[server.SecretKeyPassword]) > 0 {
+		if len(secret.Data[meta.SecretKeyUsername]) > 0 && len(secret.Data[meta.SecretKeyPassword]) > 0 {
 			tester.t.Log("Nexus Operator credentials found! Test OK.")
 			return true, nil
 		}